@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/heathcliff26/valkey-keepalived/pkg/config"
+	failoverclient "github.com/heathcliff26/valkey-keepalived/pkg/failover-client"
+	"github.com/spf13/cobra"
+)
+
+const (
+	flagNameNode          = "node"
+	flagNameMinKeys       = "min-keys"
+	flagNameMinOffset     = "min-offset"
+	flagNameRequireLinkUp = "require-link-up"
+
+	defaultCheckNode = "localhost"
+)
+
+func newCheckCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "check",
+		Short: "Check the health of a single node and exit with 1 if it is unhealthy, meant for the vrrp_script of keepalived",
+		Args:  cobra.NoArgs,
+		// A failed check is an expected result, not a usage error
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			cfgPath, err := cmd.Flags().GetString(flagNameConfig)
+			if err != nil {
+				return err
+			}
+			env, err := cmd.Flags().GetBool(flagNameEnv)
+			if err != nil {
+				return err
+			}
+			timeout, err := cmd.Flags().GetDuration(flagNameTimeout)
+			if err != nil {
+				return err
+			}
+
+			var opts failoverclient.CheckOptions
+			opts.Node, err = cmd.Flags().GetString(flagNameNode)
+			if err != nil {
+				return err
+			}
+			opts.MinKeys, err = cmd.Flags().GetInt64(flagNameMinKeys)
+			if err != nil {
+				return err
+			}
+			opts.MinOffset, err = cmd.Flags().GetInt64(flagNameMinOffset)
+			if err != nil {
+				return err
+			}
+			opts.RequireLinkUp, err = cmd.Flags().GetBool(flagNameRequireLinkUp)
+			if err != nil {
+				return err
+			}
+
+			cfg, err := config.LoadConfig(cfgPath, env)
+			if err != nil {
+				return err
+			}
+
+			res, err := failoverclient.CheckNode(cfg.Valkey, opts, timeout)
+			if err != nil {
+				return err
+			}
+			cmd.Printf("Node %s is healthy: role=%s keys=%d offset=%d\n", res.Node, res.Role, res.Keys, res.Offset)
+			return nil
+		},
+	}
+
+	cmd.Flags().String(flagNameNode, defaultCheckNode, "Name or address of the node to check, nodes not in the config use the global connection settings")
+	cmd.Flags().Int64(flagNameMinKeys, 0, "Minimum number of keys the node needs to hold")
+	cmd.Flags().Int64(flagNameMinOffset, 0, "Minimum replication offset the node needs to have reached")
+	cmd.Flags().Bool(flagNameRequireLinkUp, false, "Fail when the node is a slave with the replication link down")
+	cmd.Flags().Duration(flagNameTimeout, 2*time.Second, "Timeout for checking the node")
+
+	return cmd
+}
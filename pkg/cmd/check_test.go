@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewCheckCommand(t *testing.T) {
+	cmd := newCheckCommand()
+
+	assert := assert.New(t)
+
+	assert.Equal("check", cmd.Use)
+	for _, name := range []string{flagNameNode, flagNameMinKeys, flagNameMinOffset, flagNameRequireLinkUp, flagNameTimeout} {
+		assert.NotNil(cmd.Flags().Lookup(name), "Should have %s flag", name)
+	}
+	assert.Equal(defaultCheckNode, cmd.Flags().Lookup(flagNameNode).DefValue, "Should check the local node by default")
+}
+
+func TestCheckCommand(t *testing.T) {
+	t.Run("InvalidConfig", func(t *testing.T) {
+		_, err := executeCommand("check", "-c", "../config/testdata/invalid-config-valkey.yaml")
+		assert.Error(t, err, "Should fail with an invalid config")
+	})
+	t.Run("Unreachable", func(t *testing.T) {
+		out, err := executeCommand("check", "-c", "../config/testdata/valid-config-defaults.yaml", "--node", "127.0.0.1:1", "--timeout", "1s")
+		assert.ErrorContains(t, err, "is not reachable", "Should fail when the node is down")
+		assert.NotContains(t, out, "Usage:", "Should not print the usage on a failed check")
+	})
+}
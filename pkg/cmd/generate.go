@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/heathcliff26/valkey-keepalived/pkg/config"
+	"github.com/heathcliff26/valkey-keepalived/pkg/keepalived"
+	"github.com/spf13/cobra"
+)
+
+const (
+	flagNameInstance        = "instance"
+	flagNameInterface       = "interface"
+	flagNameVirtualRouterID = "virtual-router-id"
+	flagNameBinary          = "binary"
+	flagNameTemplate        = "template"
+)
+
+func newGenerateCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "generate",
+		Short: "Generate the configuration of other tools from the config file",
+	}
+
+	cmd.AddCommand(newGenerateKeepalivedCommand())
+
+	return cmd
+}
+
+func newGenerateKeepalivedCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "keepalived",
+		Short: "Print the keepalived config for a single node",
+		Long:  "Print the keepalived config for a single node.\nThe priority of the node is derived from its position in the config, the first node has the highest priority.\nThe rendered config contains a vrrp_script with the check command and, when the notify socket is enabled, a notify script.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			var opts keepalived.Options
+			var err error
+
+			opts.ConfigPath, err = cmd.Flags().GetString(flagNameConfig)
+			if err != nil {
+				return err
+			}
+			opts.Env, err = cmd.Flags().GetBool(flagNameEnv)
+			if err != nil {
+				return err
+			}
+			opts.Node, err = cmd.Flags().GetString(flagNameNode)
+			if err != nil {
+				return err
+			}
+			opts.Instance, err = cmd.Flags().GetString(flagNameInstance)
+			if err != nil {
+				return err
+			}
+			opts.Interface, err = cmd.Flags().GetString(flagNameInterface)
+			if err != nil {
+				return err
+			}
+			opts.VirtualRouterID, err = cmd.Flags().GetInt64(flagNameVirtualRouterID)
+			if err != nil {
+				return err
+			}
+			opts.Binary, err = cmd.Flags().GetString(flagNameBinary)
+			if err != nil {
+				return err
+			}
+			templatePath, err := cmd.Flags().GetString(flagNameTemplate)
+			if err != nil {
+				return err
+			}
+
+			cfg, err := config.LoadConfig(opts.ConfigPath, opts.Env)
+			if err != nil {
+				return err
+			}
+
+			if opts.ConfigPath == "" {
+				opts.ConfigPath = config.DEFAULT_CONFIG_PATH
+			}
+			// keepalived runs the scripts from a different working directory
+			opts.ConfigPath, err = filepath.Abs(opts.ConfigPath)
+			if err != nil {
+				return err
+			}
+
+			if templatePath != "" {
+				// #nosec G304: Local users can decide on the template path freely.
+				tmpl, err := os.ReadFile(templatePath)
+				if err != nil {
+					return fmt.Errorf("failed to read template: %w", err)
+				}
+				opts.Template = string(tmpl)
+			}
+
+			return keepalived.Generate(cmd.OutOrStdout(), cfg, opts)
+		},
+	}
+
+	cmd.Flags().String(flagNameNode, "", "Name or address of the node to generate the config for")
+	cmd.Flags().String(flagNameInstance, keepalived.DEFAULT_INSTANCE, "Name of the vrrp_instance")
+	cmd.Flags().String(flagNameInterface, keepalived.DEFAULT_INTERFACE, "Interface the virtual address is assigned to")
+	cmd.Flags().Int64(flagNameVirtualRouterID, keepalived.DEFAULT_VIRTUAL_ROUTER_ID, "Virtual router id of the vrrp_instance, needs to be the same on all nodes")
+	cmd.Flags().String(flagNameBinary, keepalived.DEFAULT_BINARY, "Path to the valkey-keepalived binary used in the scripts")
+	cmd.Flags().String(flagNameTemplate, "", "Path to a custom template, see the README for the available values")
+	_ = cmd.MarkFlagRequired(flagNameNode)
+
+	return cmd
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateKeepalivedCommand(t *testing.T) {
+	t.Run("DefaultTemplate", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		out, err := executeCommand("generate", "keepalived", "-c", "../config/testdata/valid-config-nodes.yaml", "--node", "node2", "--interface", "ens3")
+		require.NoError(err, "Should generate the config")
+
+		cfgPath, err := filepath.Abs("../config/testdata/valid-config-nodes.yaml")
+		require.NoError(err)
+		assert.Contains(out, "check -c "+cfgPath+" --node node2", "Should reference the absolute config path")
+		assert.Contains(out, "interface ens3", "Should use the interface")
+		assert.Contains(out, "priority 190", "Should use the priority of the second node")
+	})
+	t.Run("CustomTemplate", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		tmpl := filepath.Join(t.TempDir(), "keepalived.conf.tmpl")
+		require.NoError(os.WriteFile(tmpl, []byte("{{ .Node.Name }} {{ .State }}"), 0600), "Should write template")
+
+		out, err := executeCommand("generate", "keepalived", "-c", "../config/testdata/valid-config-nodes.yaml", "--node", "10.8.0.11", "--template", tmpl)
+		require.NoError(err, "Should generate the config")
+		assert.Equal("10.8.0.11:6379 MASTER", out, "Should use the custom template")
+	})
+	t.Run("MissingTemplate", func(t *testing.T) {
+		_, err := executeCommand("generate", "keepalived", "-c", "../config/testdata/valid-config-nodes.yaml", "--node", "node2", "--template", filepath.Join(t.TempDir(), "missing"))
+		assert.ErrorContains(t, err, "failed to read template")
+	})
+	t.Run("MissingNode", func(t *testing.T) {
+		_, err := executeCommand("generate", "keepalived", "-c", "../config/testdata/valid-config-nodes.yaml")
+		assert.ErrorContains(t, err, "required flag(s) \"node\" not set")
+	})
+}
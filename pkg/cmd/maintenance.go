@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/heathcliff26/valkey-keepalived/pkg/config"
+	failoverclient "github.com/heathcliff26/valkey-keepalived/pkg/failover-client"
+	"github.com/spf13/cobra"
+)
+
+const (
+	flagNameDuration = "duration"
+	flagNameURL      = "url"
+	flagNameToken    = "token"
+)
+
+func newPauseCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "pause",
+		Short: "Pause the reconciliation of the running instance, the nodes are still checked but not changed",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			duration, err := cmd.Flags().GetDuration(flagNameDuration)
+			if err != nil {
+				return err
+			}
+			if duration < 0 {
+				return fmt.Errorf("invalid duration, can't be negative")
+			}
+
+			path := "/pause"
+			if duration > 0 {
+				path += "?duration=" + url.QueryEscape(duration.String())
+			}
+
+			var res failoverclient.Pause
+			err = postServer(cmd, path, &res)
+			if err != nil {
+				return err
+			}
+
+			if res.Until.IsZero() {
+				cmd.Println("Reconciliation is paused until resumed")
+			} else {
+				cmd.Printf("Reconciliation is paused until %s\n", res.Until.Format(time.RFC3339))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().Duration(flagNameDuration, 0, "How long to pause, defaults to the configured pause timeout")
+	addServerFlags(cmd)
+
+	return cmd
+}
+
+func newResumeCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "resume",
+		Short: "Resume the reconciliation of the running instance",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			err := postServer(cmd, "/resume", nil)
+			if err != nil {
+				return err
+			}
+			cmd.Println("Reconciliation is resumed")
+			return nil
+		},
+	}
+
+	addServerFlags(cmd)
+
+	return cmd
+}
+
+// Add the flags for reaching the http server of the running instance
+func addServerFlags(cmd *cobra.Command) {
+	cmd.Flags().String(flagNameURL, "", "Base url of the http server, defaults to the address and port from the config")
+	cmd.Flags().String(flagNameToken, "", "Admin token of the http server, defaults to the token from the config when no url is given")
+	cmd.Flags().Duration(flagNameTimeout, 5*time.Second, "Timeout for the request")
+}
+
+// Return the base url and admin token of the http server, either from the flags or the config.
+// The config is only read when no url is given.
+func serverURL(cmd *cobra.Command) (string, string, error) {
+	baseURL, err := cmd.Flags().GetString(flagNameURL)
+	if err != nil {
+		return "", "", err
+	}
+	token, err := cmd.Flags().GetString(flagNameToken)
+	if err != nil {
+		return "", "", err
+	}
+	if baseURL != "" {
+		return strings.TrimSuffix(baseURL, "/"), token, nil
+	}
+
+	cfgPath, err := cmd.Flags().GetString(flagNameConfig)
+	if err != nil {
+		return "", "", err
+	}
+	env, err := cmd.Flags().GetBool(flagNameEnv)
+	if err != nil {
+		return "", "", err
+	}
+
+	cfg, err := config.LoadConfig(cfgPath, env)
+	if err != nil {
+		return "", "", err
+	}
+	if !cfg.Server.Enabled {
+		return "", "", fmt.Errorf("the http server is disabled in the config, enable it or use --%s", flagNameURL)
+	}
+	if token == "" {
+		token = cfg.Server.AdminToken
+	}
+	host := cfg.Server.Address
+	if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+		host = "localhost"
+	}
+	return "http://" + net.JoinHostPort(host, strconv.FormatInt(cfg.Server.Port, 10)), token, nil
+}
+
+// Send a POST request to the http server of the running instance and decode the json response into res
+func postServer(cmd *cobra.Command, path string, res any) error {
+	baseURL, token, err := serverURL(cmd)
+	if err != nil {
+		return err
+	}
+	timeout, err := cmd.Flags().GetDuration(flagNameTimeout)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(cmd.Context(), http.MethodPost, baseURL+path, nil)
+	if err != nil {
+		return err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	client := &http.Client{Timeout: timeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("request failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	if res == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(res)
+}
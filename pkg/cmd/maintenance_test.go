@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	failoverclient "github.com/heathcliff26/valkey-keepalived/pkg/failover-client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Start a fake http server recording the requests it received
+func newFakeMaintenanceServer(t *testing.T, until time.Time) (*httptest.Server, *[]string) {
+	var requests []string
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		requests = append(requests, req.Method+" "+req.URL.String()+" "+req.Header.Get("Authorization"))
+		switch req.URL.Path {
+		case "/pause":
+			_ = json.NewEncoder(rw).Encode(failoverclient.Pause{Since: time.Now(), Until: until})
+		case "/resume":
+			_, _ = rw.Write([]byte("null\n"))
+		default:
+			http.NotFound(rw, req)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &requests
+}
+
+func runMaintenanceCommand(args ...string) (string, error) {
+	cmd := NewRootCommand()
+	cmd.SetArgs(args)
+	out := &bytes.Buffer{}
+	cmd.SetOut(out)
+	cmd.SetErr(out)
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+func TestPauseCommand(t *testing.T) {
+	t.Run("Duration", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		until := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+		srv, requests := newFakeMaintenanceServer(t, until)
+
+		out, err := runMaintenanceCommand("pause", "--url", srv.URL+"/", "--duration", "10m")
+		require.NoError(err, "Should pause")
+		assert.Equal([]string{"POST /pause?duration=10m0s "}, *requests, "Should send the duration")
+		assert.Contains(out, "paused until 2025-01-01T12:00:00Z", "Should print the expiry")
+	})
+	t.Run("NoExpiry", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		srv, requests := newFakeMaintenanceServer(t, time.Time{})
+
+		out, err := runMaintenanceCommand("pause", "--url", srv.URL)
+		require.NoError(err, "Should pause")
+		assert.Equal([]string{"POST /pause "}, *requests, "Should use the default duration")
+		assert.Contains(out, "paused until resumed", "Should print that the pause does not expire")
+	})
+	t.Run("NegativeDuration", func(t *testing.T) {
+		srv, requests := newFakeMaintenanceServer(t, time.Time{})
+
+		_, err := runMaintenanceCommand("pause", "--url", srv.URL, "--duration", "-1m")
+		assert.Error(t, err, "Should fail")
+		assert.Empty(t, *requests, "Should not send a request")
+	})
+}
+
+func TestPauseCommandToken(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	srv, requests := newFakeMaintenanceServer(t, time.Time{})
+
+	_, err := runMaintenanceCommand("pause", "--url", srv.URL, "--token", "secret")
+	require.NoError(err, "Should pause")
+	assert.Equal([]string{"POST /pause Bearer secret"}, *requests, "Should send the admin token")
+}
+
+func TestResumeCommand(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	srv, requests := newFakeMaintenanceServer(t, time.Time{})
+
+	out, err := runMaintenanceCommand("resume", "--url", srv.URL)
+	require.NoError(err, "Should resume")
+	assert.Equal([]string{"POST /resume "}, *requests, "Should send the request")
+	assert.Contains(out, "resumed", "Should print the result")
+}
+
+func TestServerURL(t *testing.T) {
+	tMatrix := map[string]struct {
+		args   []string
+		result string
+		token  string
+		err    bool
+	}{
+		"Flag": {
+			args:   []string{"--url", "http://example.com:8080/", "--token", "secret"},
+			result: "http://example.com:8080",
+			token:  "secret",
+		},
+		"FlagWithoutToken": {
+			args:   []string{"--url", "http://example.com:8080/", "-c", "../config/testdata/valid-config.yaml"},
+			result: "http://example.com:8080",
+		},
+		"Config": {
+			args:   []string{"-c", "../config/testdata/valid-config.yaml"},
+			result: "http://127.0.0.1:9000",
+			token:  "testtoken",
+		},
+		"ConfigTokenFlag": {
+			args:   []string{"-c", "../config/testdata/valid-config.yaml", "--token", "secret"},
+			result: "http://127.0.0.1:9000",
+			token:  "secret",
+		},
+		"ServerDisabled": {
+			args: []string{"-c", "../config/testdata/valid-config-defaults.yaml"},
+			err:  true,
+		},
+		"MissingConfig": {
+			args: []string{"-c", "not-a-file.yaml"},
+			err:  true,
+		},
+	}
+
+	for name, tCase := range tMatrix {
+		t.Run(name, func(t *testing.T) {
+			cmd := NewRootCommand()
+			pause, _, err := cmd.Find([]string{"pause"})
+			require.NoError(t, err, "Should find the pause command")
+			require.NoError(t, pause.ParseFlags(tCase.args), "Should parse the flags")
+
+			res, token, err := serverURL(pause)
+			if tCase.err {
+				assert.Error(t, err, "Should fail")
+				return
+			}
+			assert.NoError(t, err, "Should succeed")
+			assert.Equal(t, tCase.result, res, "Should return the base url")
+			assert.Equal(t, tCase.token, token, "Should return the admin token")
+		})
+	}
+}
+
+func TestPostServerError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		http.Error(rw, "invalid duration", http.StatusBadRequest)
+	}))
+	t.Cleanup(srv.Close)
+
+	_, err := runMaintenanceCommand("resume", "--url", srv.URL)
+	if assert.Error(t, err, "Should fail on error status") {
+		assert.Contains(t, err.Error(), "invalid duration", "Should contain the response body")
+	}
+}
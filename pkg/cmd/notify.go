@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+	"time"
+
+	"github.com/heathcliff26/valkey-keepalived/pkg/config"
+	"github.com/heathcliff26/valkey-keepalived/pkg/notify"
+	"github.com/spf13/cobra"
+)
+
+const flagNameSocket = "socket"
+
+func newNotifyCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "notify [event...]",
+		Short: "Trigger an immediate check in the running instance, meant for the notify scripts of keepalived",
+		Long:  "Trigger an immediate check in the running instance, meant for the notify scripts of keepalived.\nThe given arguments are only logged by the running instance, so the arguments keepalived passes to notify scripts can be used as is.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			socket, err := notifySocket(cmd)
+			if err != nil {
+				return err
+			}
+			timeout, err := cmd.Flags().GetDuration(flagNameTimeout)
+			if err != nil {
+				return err
+			}
+
+			return notify.Send(socket, strings.Join(args, " "), timeout)
+		},
+	}
+
+	cmd.Flags().String(flagNameSocket, "", "Path to the notify socket of the running instance, defaults to the socket from the config")
+	cmd.Flags().Duration(flagNameTimeout, 5*time.Second, "Timeout for sending the notification")
+
+	return cmd
+}
+
+// Return the path of the notify socket, either from the flag or the config.
+// Falls back to the default socket when no config is given and the default config does not exist.
+func notifySocket(cmd *cobra.Command) (string, error) {
+	socket, err := cmd.Flags().GetString(flagNameSocket)
+	if err != nil {
+		return "", err
+	}
+	if socket != "" {
+		return socket, nil
+	}
+
+	cfgPath, err := cmd.Flags().GetString(flagNameConfig)
+	if err != nil {
+		return "", err
+	}
+	env, err := cmd.Flags().GetBool(flagNameEnv)
+	if err != nil {
+		return "", err
+	}
+
+	cfg, err := config.LoadConfig(cfgPath, env)
+	if cfgPath == "" && errors.Is(err, fs.ErrNotExist) {
+		return notify.DEFAULT_SOCKET, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if !cfg.Notify.Enabled {
+		return "", fmt.Errorf("the notify socket is disabled in the config, enable it or use --%s", flagNameSocket)
+	}
+	return cfg.Notify.Socket, nil
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/heathcliff26/valkey-keepalived/pkg/notify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotifyCommand(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	socket := filepath.Join(t.TempDir(), "notify.sock")
+	notified := 0
+	l, err := notify.NewListener(socket, func() {
+		notified++
+	})
+	require.NoError(err, "Should create listener")
+	go l.Run()
+	t.Cleanup(func() {
+		l.Close()
+	})
+
+	_, err = executeCommand("notify", "--socket", socket, "INSTANCE", "VI_1", "MASTER", "100")
+	require.NoError(err, "Should send the notification")
+	assert.Equal(1, notified, "Should notify the running instance")
+
+	_, err = executeCommand("notify", "--socket", filepath.Join(t.TempDir(), "missing.sock"))
+	assert.Error(err, "Should fail without running instance")
+}
+
+func TestNotifySocket(t *testing.T) {
+	tMatrix := map[string]struct {
+		args   []string
+		result string
+		err    bool
+	}{
+		"Flag": {
+			args:   []string{"--socket", "/tmp/notify.sock", "-c", "not-a-file.yaml"},
+			result: "/tmp/notify.sock",
+		},
+		"Config": {
+			args:   []string{"-c", "../config/testdata/valid-config.yaml"},
+			result: "/run/keepalived/valkey.sock",
+		},
+		"NotifyDisabled": {
+			args: []string{"-c", "../config/testdata/valid-config-defaults.yaml"},
+			err:  true,
+		},
+		"MissingConfig": {
+			args: []string{"-c", "not-a-file.yaml"},
+			err:  true,
+		},
+	}
+
+	for name, tCase := range tMatrix {
+		t.Run(name, func(t *testing.T) {
+			cmd := NewRootCommand()
+			notifyCmd, _, err := cmd.Find([]string{"notify"})
+			require.NoError(t, err, "Should find the notify command")
+			require.NoError(t, notifyCmd.ParseFlags(tCase.args), "Should parse the flags")
+
+			res, err := notifySocket(notifyCmd)
+			if tCase.err {
+				assert.Error(t, err, "Should fail")
+				return
+			}
+			assert.NoError(t, err, "Should succeed")
+			assert.Equal(t, tCase.result, res, "Should return the socket")
+		})
+	}
+}
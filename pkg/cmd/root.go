@@ -1,19 +1,39 @@
 package cmd
 
 import (
+	"context"
+	"log/slog"
 	"os"
 
 	"github.com/heathcliff26/valkey-keepalived/pkg/config"
 	failoverclient "github.com/heathcliff26/valkey-keepalived/pkg/failover-client"
+	"github.com/heathcliff26/valkey-keepalived/pkg/notify"
+	"github.com/heathcliff26/valkey-keepalived/pkg/sentinel"
+	"github.com/heathcliff26/valkey-keepalived/pkg/server"
 	"github.com/heathcliff26/valkey-keepalived/pkg/version"
 	"github.com/spf13/cobra"
 )
 
 const (
-	flagNameConfig = "config"
-	flagNameEnv    = "env"
+	flagNameConfig      = "config"
+	flagNameEnv         = "env"
+	flagNameWatchConfig = "watch-config"
+
+	flagNameAllowEmptyPromotion = "allow-empty-promotion"
+	flagNameDryRun              = "dry-run"
 )
 
+// The options for running the failover client
+type runOptions struct {
+	configPath string
+	env        bool
+	watch      bool
+
+	// Overrides for the loaded configuration
+	allowEmptyPromotion bool
+	dryRun              bool
+}
+
 func NewRootCommand() *cobra.Command {
 	cobra.AddTemplateFunc(
 		"ProgramName", func() string {
@@ -25,31 +45,58 @@ func NewRootCommand() *cobra.Command {
 		Use:   version.Name,
 		Short: version.Name + " failover a group of valkey databases based on a virtual ip",
 		RunE: func(cmd *cobra.Command, _ []string) error {
-			cfg, err := cmd.Flags().GetString(flagNameConfig)
+			var opts runOptions
+			var err error
+
+			opts.configPath, err = cmd.Flags().GetString(flagNameConfig)
+			if err != nil {
+				return err
+			}
+
+			opts.env, err = cmd.Flags().GetBool(flagNameEnv)
+			if err != nil {
+				return err
+			}
+
+			opts.watch, err = cmd.Flags().GetBool(flagNameWatchConfig)
+			if err != nil {
+				return err
+			}
+
+			opts.allowEmptyPromotion, err = cmd.Flags().GetBool(flagNameAllowEmptyPromotion)
 			if err != nil {
 				return err
 			}
 
-			env, err := cmd.Flags().GetBool(flagNameEnv)
+			opts.dryRun, err = cmd.Flags().GetBool(flagNameDryRun)
 			if err != nil {
 				return err
 			}
 
-			run(cmd, cfg, env)
+			run(cmd, opts)
 			return nil
 		},
 	}
 
-	rootCmd.Flags().StringP(flagNameConfig, "c", "", "Path to config file")
-	err := rootCmd.MarkFlagFilename(flagNameConfig, "yaml", "yml")
+	rootCmd.PersistentFlags().StringP(flagNameConfig, "c", "", "Path to config file")
+	err := rootCmd.MarkPersistentFlagFilename(flagNameConfig, "yaml", "yml")
 	if err != nil {
 		rootCmd.PrintErrln("Fatal: " + err.Error())
 		os.Exit(1)
 	}
 
-	rootCmd.Flags().Bool(flagNameEnv, false, "Expand enviroment variables in config file")
+	rootCmd.PersistentFlags().Bool(flagNameEnv, false, "Expand enviroment variables in config file")
+	rootCmd.Flags().Bool(flagNameWatchConfig, false, "Reload the config automatically when the file changes")
+	rootCmd.Flags().Bool(flagNameAllowEmptyPromotion, false, "Promote the node behind the virtual address, even when it is empty while other nodes hold data")
+	rootCmd.Flags().Bool(flagNameDryRun, false, "Only log the changes to the nodes instead of executing them")
 
 	rootCmd.AddCommand(
+		newCheckCommand(),
+		newGenerateCommand(),
+		newStatusCommand(),
+		newPauseCommand(),
+		newResumeCommand(),
+		newNotifyCommand(),
 		version.NewCommand(),
 	)
 
@@ -65,12 +112,92 @@ func Execute() {
 	}
 }
 
-func run(cmd *cobra.Command, configPath string, env bool) {
-	cfg, err := config.LoadConfig(configPath, env)
+func run(cmd *cobra.Command, opts runOptions) {
+	cfg, err := opts.loadConfig()
+	if err != nil {
+		cmd.PrintErrln("Fatal: " + err.Error())
+		os.Exit(1)
+	}
+	cfg.ApplyLogLevel()
+
+	client, err := failoverclient.NewFailoverClient(cfg.Valkey)
 	if err != nil {
 		cmd.PrintErrln("Fatal: " + err.Error())
 		os.Exit(1)
 	}
+	client.SetConfigLoader(func() (failoverclient.ValkeyConfig, func(), error) {
+		cfg, err := opts.loadConfig()
+		return cfg.Valkey, cfg.ApplyLogLevel, err
+	})
 
-	failoverclient.NewFailoverClient(cfg.Valkey).Run()
+	if opts.watch {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go config.NewWatcher(opts.configPath, client.Reload).Run(ctx)
+	}
+
+	if cfg.Server.Enabled {
+		s := server.NewServer(cfg.Server, client)
+		go func() {
+			err := s.Run()
+			if err != nil {
+				slog.Error("Failed to run http server", "err", err)
+				os.Exit(1)
+			}
+		}()
+		defer func() {
+			err := s.Shutdown()
+			if err != nil {
+				slog.Error("Failed to shutdown http server", "err", err)
+			}
+		}()
+	}
+
+	if cfg.Notify.Enabled {
+		l, err := notify.NewListener(cfg.Notify.Socket, client.Trigger)
+		if err != nil {
+			cmd.PrintErrln("Fatal: failed to create notify socket: " + err.Error())
+			os.Exit(1)
+		}
+		go l.Run()
+		defer func() {
+			err := l.Close()
+			if err != nil {
+				slog.Error("Failed to close notify socket", "err", err)
+			}
+		}()
+	}
+
+	if cfg.Sentinel.Enabled {
+		s, err := sentinel.NewServer(cfg.Sentinel, client)
+		if err != nil {
+			cmd.PrintErrln("Fatal: failed to start sentinel listener: " + err.Error())
+			os.Exit(1)
+		}
+		client.OnSwitchover(s.PublishSwitchover)
+		go s.Run()
+		defer func() {
+			err := s.Close()
+			if err != nil {
+				slog.Error("Failed to close sentinel listener", "err", err)
+			}
+		}()
+	}
+
+	client.Run()
+}
+
+// Load the config and apply the overrides from the command line
+func (o runOptions) loadConfig() (config.Config, error) {
+	cfg, err := config.LoadConfig(o.configPath, o.env)
+	if err != nil {
+		return cfg, err
+	}
+	if o.allowEmptyPromotion {
+		cfg.Valkey.AllowEmptyPromotion = true
+	}
+	if o.dryRun {
+		cfg.Valkey.DryRun = true
+	}
+	return cfg, nil
 }
@@ -1,12 +1,14 @@
 package cmd
 
 import (
+	"bytes"
 	"os"
 	"os/exec"
 	"testing"
 
 	"github.com/heathcliff26/valkey-keepalived/pkg/version"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewRootCommand(t *testing.T) {
@@ -24,6 +26,29 @@ func TestCommandRun(t *testing.T) {
 	execExitTest(t, "TestCommandRun", true)
 }
 
+func TestRunOptionsLoadConfig(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	opts := runOptions{configPath: "../config/testdata/valid-config-defaults.yaml"}
+
+	cfg, err := opts.loadConfig()
+	require.NoError(err, "Should load config")
+	assert.False(cfg.Valkey.AllowEmptyPromotion, "Should not allow empty promotion by default")
+	assert.False(cfg.Valkey.DryRun, "Should not use dry-run by default")
+
+	opts.allowEmptyPromotion = true
+	opts.dryRun = true
+	cfg, err = opts.loadConfig()
+	require.NoError(err, "Should load config")
+	assert.True(cfg.Valkey.AllowEmptyPromotion, "Should override the config")
+	assert.True(cfg.Valkey.DryRun, "Should enable dry-run")
+
+	opts.configPath = "not-a-file.yaml"
+	_, err = opts.loadConfig()
+	assert.Error(err, "Should return the error from loading the config")
+}
+
 func TestExecute(t *testing.T) {
 	if os.Getenv("RUN_CRASH_TEST") == "1" {
 		oldArgs := os.Args
@@ -50,3 +75,14 @@ func execExitTest(t *testing.T, test string, exitsError bool) {
 	}
 	t.Fatalf("process ran with err %v, want exit status 1", err)
 }
+
+// Run the root command with the given arguments and return the output
+func executeCommand(args ...string) (string, error) {
+	cmd := NewRootCommand()
+	cmd.SetArgs(args)
+	out := &bytes.Buffer{}
+	cmd.SetOut(out)
+	cmd.SetErr(out)
+	err := cmd.Execute()
+	return out.String(), err
+}
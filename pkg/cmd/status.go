@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/heathcliff26/valkey-keepalived/pkg/config"
+	failoverclient "github.com/heathcliff26/valkey-keepalived/pkg/failover-client"
+	"github.com/spf13/cobra"
+)
+
+const (
+	flagNameOutput  = "output"
+	flagNameTimeout = "timeout"
+
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+func newStatusCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Query all nodes once and print the current topology",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			cfgPath, err := cmd.Flags().GetString(flagNameConfig)
+			if err != nil {
+				return err
+			}
+			env, err := cmd.Flags().GetBool(flagNameEnv)
+			if err != nil {
+				return err
+			}
+			output, err := cmd.Flags().GetString(flagNameOutput)
+			if err != nil {
+				return err
+			}
+			timeout, err := cmd.Flags().GetDuration(flagNameTimeout)
+			if err != nil {
+				return err
+			}
+
+			if output != outputTable && output != outputJSON {
+				return fmt.Errorf("unknown output format \"%s\"", output)
+			}
+
+			cfg, err := config.LoadConfig(cfgPath, env)
+			if err != nil {
+				return err
+			}
+
+			topology, err := failoverclient.QueryTopology(cfg.Valkey, timeout)
+			if err != nil {
+				return err
+			}
+			return printTopology(cmd.OutOrStdout(), topology, output)
+		},
+	}
+
+	cmd.Flags().StringP(flagNameOutput, "o", outputTable, "Output format, one of: table, json")
+	cmd.Flags().Duration(flagNameTimeout, 5*time.Second, "Timeout for querying the nodes")
+
+	return cmd
+}
+
+// Print the topology in the given format
+func printTopology(out io.Writer, topology failoverclient.Topology, format string) error {
+	if format == outputJSON {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(topology)
+	}
+
+	if topology.VirtualAddressError != "" {
+		fmt.Fprintf(out, "Virtual address %s is unreachable: %s\n\n", topology.VirtualAddress, topology.VirtualAddressError)
+	} else {
+		fmt.Fprintf(out, "Virtual address %s points to run_id %s\n\n", topology.VirtualAddress, topology.VirtualAddressRunID)
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VIP\tNAME\tNODE\tREACHABLE\tRUN_ID\tROLE\tMASTER\tLINK\tOFFSET\tERROR")
+	for _, n := range topology.Nodes {
+		vip := ""
+		if n.BehindVirtualAddress {
+			vip = "*"
+		}
+		masterAddr := ""
+		if n.MasterHost != "" {
+			masterAddr = net.JoinHostPort(n.MasterHost, strconv.FormatInt(n.MasterPort, 10))
+		}
+		nodeAddr := net.JoinHostPort(n.Address, strconv.FormatInt(n.Port, 10))
+		fmt.Fprintf(w, "%s\t%s\t%s\t%t\t%s\t%s\t%s\t%s\t%d\t%s\n", vip, n.Name, nodeAddr, n.Reachable, n.RunID, n.Role, masterAddr, n.MasterLinkStatus, n.ReplicationOffset, n.Error)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	failoverclient "github.com/heathcliff26/valkey-keepalived/pkg/failover-client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testTopology = failoverclient.Topology{
+	VirtualAddress:      "10.8.0.10",
+	VirtualAddressRunID: "runid1",
+	Nodes: []failoverclient.NodeTopology{
+		{
+			Address:              "10.8.0.11",
+			Port:                 6379,
+			Reachable:            true,
+			RunID:                "runid1",
+			Role:                 "master",
+			ReplicationOffset:    1234,
+			BehindVirtualAddress: true,
+		},
+		{
+			Address:           "10.8.0.12",
+			Port:              6379,
+			Reachable:         true,
+			RunID:             "runid2",
+			Role:              "slave",
+			MasterHost:        "10.8.0.11",
+			MasterPort:        6379,
+			MasterLinkStatus:  "up",
+			ReplicationOffset: 1200,
+		},
+		{
+			Address: "10.8.0.13",
+			Port:    6379,
+			Error:   "connection refused",
+		},
+	},
+}
+
+func TestNewStatusCommand(t *testing.T) {
+	cmd := newStatusCommand()
+
+	assert := assert.New(t)
+
+	assert.Equal("status", cmd.Use)
+	assert.NotNil(cmd.Flags().Lookup(flagNameOutput), "Should have output flag")
+	assert.NotNil(cmd.Flags().Lookup(flagNameTimeout), "Should have timeout flag")
+}
+
+func TestStatusCommandInvalidOutput(t *testing.T) {
+	cmd := NewRootCommand()
+	cmd.SetArgs([]string{"status", "-o", "yaml"})
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+
+	err := cmd.Execute()
+
+	assert.EqualError(t, err, "unknown output format \"yaml\"")
+}
+
+func TestPrintTopologyTable(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	var out bytes.Buffer
+	err := printTopology(&out, testTopology, outputTable)
+	require.NoError(err, "Should print table")
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(lines, 6, "Should print header and all nodes")
+	assert.Contains(lines[0], "run_id runid1", "Should print the run_id behind the virtual address")
+	assert.True(strings.HasPrefix(lines[3], "*"), "Should highlight the node behind the virtual address")
+	assert.Contains(lines[4], "10.8.0.11:6379", "Should print master of the slave")
+	assert.Contains(lines[4], "up", "Should print the link status")
+	assert.Contains(lines[5], "connection refused", "Should print the error")
+}
+
+func TestPrintTopologyJSON(t *testing.T) {
+	require := require.New(t)
+
+	var out bytes.Buffer
+	err := printTopology(&out, testTopology, outputJSON)
+	require.NoError(err, "Should print json")
+
+	var res failoverclient.Topology
+	require.NoError(json.Unmarshal(out.Bytes(), &res), "Should print valid json")
+	assert.Equal(t, testTopology, res, "Should contain the full topology")
+}
@@ -7,6 +7,9 @@ import (
 	"strings"
 
 	failoverclient "github.com/heathcliff26/valkey-keepalived/pkg/failover-client"
+	"github.com/heathcliff26/valkey-keepalived/pkg/notify"
+	"github.com/heathcliff26/valkey-keepalived/pkg/sentinel"
+	"github.com/heathcliff26/valkey-keepalived/pkg/server"
 	"go.yaml.in/yaml/v3"
 )
 
@@ -32,6 +35,9 @@ func init() {
 type Config struct {
 	LogLevel string                      `yaml:"logLevel,omitempty"`
 	Valkey   failoverclient.ValkeyConfig `yaml:"valkey"`
+	Server   server.Config               `yaml:"server,omitempty"`
+	Notify   notify.Config               `yaml:"notify,omitempty"`
+	Sentinel sentinel.Config             `yaml:"sentinel,omitempty"`
 }
 
 // Returns a Config with default values set
@@ -39,7 +45,28 @@ func DefaultConfig() Config {
 	return Config{
 		LogLevel: DEFAULT_LOG_LEVEL,
 		Valkey: failoverclient.ValkeyConfig{
-			Port: DEFAULT_PORT,
+			Port:            DEFAULT_PORT,
+			CheckInterval:   failoverclient.DEFAULT_CHECK_INTERVAL,
+			Timeout:         failoverclient.DEFAULT_TIMEOUT,
+			RoleCacheTTL:    failoverclient.DEFAULT_ROLE_CACHE_TTL,
+			LinkDownTimeout: failoverclient.DEFAULT_LINK_DOWN_TIMEOUT,
+			Fencing: failoverclient.FencingConfig{
+				PauseTimeout: failoverclient.DEFAULT_FENCING_PAUSE_TIMEOUT,
+			},
+			PauseTimeout: failoverclient.DEFAULT_PAUSE_TIMEOUT,
+			Dampening: failoverclient.DampeningConfig{
+				Window: failoverclient.DEFAULT_DAMPENING_WINDOW,
+			},
+		},
+		Server: server.Config{
+			Port: server.DEFAULT_PORT,
+		},
+		Notify: notify.Config{
+			Socket: notify.DEFAULT_SOCKET,
+		},
+		Sentinel: sentinel.Config{
+			Port:       sentinel.DEFAULT_PORT,
+			MasterName: sentinel.DEFAULT_MASTER_NAME,
 		},
 	}
 }
@@ -71,12 +98,28 @@ func LoadConfig(path string, env bool) (Config, error) {
 		return Config{}, err
 	}
 
-	err = setLogLevel(c.LogLevel)
+	err = c.Valkey.Validate()
 	if err != nil {
 		return Config{}, err
 	}
 
-	err = c.Valkey.Validate()
+	err = c.Server.Validate()
+	if err != nil {
+		return Config{}, err
+	}
+
+	err = c.Notify.Validate()
+	if err != nil {
+		return Config{}, err
+	}
+
+	err = c.Sentinel.Validate()
+	if err != nil {
+		return Config{}, err
+	}
+
+	// The log level is only validated here, it is applied with ApplyLogLevel once the rest of the config is in use
+	_, err = parseLogLevel(c.LogLevel)
 	if err != nil {
 		return Config{}, err
 	}
@@ -84,19 +127,37 @@ func LoadConfig(path string, env bool) (Config, error) {
 	return c, nil
 }
 
+// Set the log level from the config.
+// The config needs to be loaded with LoadConfig, an invalid level keeps the current one.
+func (c Config) ApplyLogLevel() {
+	err := setLogLevel(c.LogLevel)
+	if err != nil {
+		slog.Warn("Keeping the current log level", "err", err)
+	}
+}
+
 // Parse a given string and set the resulting log level
 func setLogLevel(level string) error {
+	l, err := parseLogLevel(level)
+	if err != nil {
+		return err
+	}
+	logLevel.Set(l)
+	return nil
+}
+
+// Parse the given log level
+func parseLogLevel(level string) (slog.Level, error) {
 	switch strings.ToLower(level) {
 	case "debug":
-		logLevel.Set(slog.LevelDebug)
+		return slog.LevelDebug, nil
 	case "info":
-		logLevel.Set(slog.LevelInfo)
+		return slog.LevelInfo, nil
 	case "warn":
-		logLevel.Set(slog.LevelWarn)
+		return slog.LevelWarn, nil
 	case "error":
-		logLevel.Set(slog.LevelError)
+		return slog.LevelError, nil
 	default:
-		return fmt.Errorf("unkown log level \"%s\"", strings.ToLower(level))
+		return 0, fmt.Errorf("unkown log level \"%s\"", strings.ToLower(level))
 	}
-	return nil
 }
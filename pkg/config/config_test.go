@@ -5,29 +5,97 @@ import (
 	"log/slog"
 	"reflect"
 	"testing"
+	"time"
 
 	failoverclient "github.com/heathcliff26/valkey-keepalived/pkg/failover-client"
+	"github.com/heathcliff26/valkey-keepalived/pkg/notify"
+	"github.com/heathcliff26/valkey-keepalived/pkg/sentinel"
+	"github.com/heathcliff26/valkey-keepalived/pkg/server"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestValidConfigs(t *testing.T) {
 	c1 := Config{
 		LogLevel: "debug",
 		Valkey: failoverclient.ValkeyConfig{
-			VirtualAddress: "10.8.0.10",
-			Port:           6380,
-			Nodes:          []string{"10.8.0.11", "10.8.0.12"},
-			Username:       "testuser",
-			Password:       "testpassword",
-			TLS:            true,
+			VirtualAddress:  "10.8.0.10",
+			Port:            6380,
+			Nodes:           []failoverclient.NodeConfig{{Address: "10.8.0.11"}, {Address: "10.8.0.12"}},
+			Username:        "testuser",
+			Password:        "testpassword",
+			TLS:             failoverclient.TLSConfig{Enabled: true},
+			CheckInterval:   500 * time.Millisecond,
+			Timeout:         2 * time.Second,
+			RoleCacheTTL:    30 * time.Second,
+			LinkDownTimeout: time.Minute,
+			Fencing: failoverclient.FencingConfig{
+				Enabled:      true,
+				PauseTimeout: 5 * time.Second,
+				KillClients:  true,
+			},
+			PauseTimeout: 30 * time.Minute,
+			Dampening: failoverclient.DampeningConfig{
+				Window: failoverclient.DEFAULT_DAMPENING_WINDOW,
+			},
+		},
+		Server: server.Config{
+			Enabled:    true,
+			Address:    "127.0.0.1",
+			Port:       9000,
+			AdminToken: "testtoken",
+		},
+		Notify: notify.Config{
+			Enabled: true,
+			Socket:  "/run/keepalived/valkey.sock",
+		},
+		Sentinel: sentinel.Config{
+			Enabled:    true,
+			Address:    "127.0.0.1",
+			Port:       26380,
+			MasterName: "valkey",
 		},
 	}
 	c2 := Config{
 		LogLevel: DEFAULT_LOG_LEVEL,
 		Valkey: failoverclient.ValkeyConfig{
-			VirtualAddress: "10.8.0.10",
-			Port:           DEFAULT_PORT,
-			Nodes:          []string{"10.8.0.11", "10.8.0.12"},
+			VirtualAddress:  "10.8.0.10",
+			Port:            DEFAULT_PORT,
+			Nodes:           []failoverclient.NodeConfig{{Address: "10.8.0.11"}, {Address: "10.8.0.12"}},
+			CheckInterval:   failoverclient.DEFAULT_CHECK_INTERVAL,
+			Timeout:         failoverclient.DEFAULT_TIMEOUT,
+			RoleCacheTTL:    failoverclient.DEFAULT_ROLE_CACHE_TTL,
+			LinkDownTimeout: failoverclient.DEFAULT_LINK_DOWN_TIMEOUT,
+			Fencing: failoverclient.FencingConfig{
+				PauseTimeout: failoverclient.DEFAULT_FENCING_PAUSE_TIMEOUT,
+			},
+			PauseTimeout: failoverclient.DEFAULT_PAUSE_TIMEOUT,
+			Dampening: failoverclient.DampeningConfig{
+				Window: failoverclient.DEFAULT_DAMPENING_WINDOW,
+			},
+		},
+		Server: server.Config{
+			Port: server.DEFAULT_PORT,
+		},
+		Notify: notify.Config{
+			Socket: notify.DEFAULT_SOCKET,
+		},
+		Sentinel: sentinel.Config{
+			Port:       sentinel.DEFAULT_PORT,
+			MasterName: sentinel.DEFAULT_MASTER_NAME,
+		},
+	}
+	c3 := DefaultConfig()
+	c3.Valkey.VirtualAddress = "10.8.0.10"
+	c3.Valkey.Nodes = []failoverclient.NodeConfig{
+		{Address: "10.8.0.11"},
+		{
+			Name:         "node2",
+			Address:      "10.8.0.12",
+			Port:         6380,
+			Username:     "node2user",
+			PasswordFile: "/run/secrets/node2-password",
+			TLS:          &failoverclient.TLSConfig{Enabled: true, ServerName: "node2.example.com"},
 		},
 	}
 	tMatrix := []struct {
@@ -44,6 +112,11 @@ func TestValidConfigs(t *testing.T) {
 			Path:   "testdata/valid-config-defaults.yaml",
 			Result: c2,
 		},
+		{
+			Name:   "ValidConfigWithNodeSettings",
+			Path:   "testdata/valid-config-nodes.yaml",
+			Result: c3,
+		},
 	}
 
 	for _, tCase := range tMatrix {
@@ -89,6 +162,21 @@ func TestInvalidConfig(t *testing.T) {
 			Path:  "testdata/invalid-config-valkey.yaml",
 			Error: "*errors.errorString",
 		},
+		{
+			Name:  "InvalidServerConfig",
+			Path:  "testdata/invalid-config-server.yaml",
+			Error: "*errors.errorString",
+		},
+		{
+			Name:  "InvalidNotifyConfig",
+			Path:  "testdata/invalid-config-notify.yaml",
+			Error: "*errors.errorString",
+		},
+		{
+			Name:  "InvalidSentinelConfig",
+			Path:  "testdata/invalid-config-sentinel.yaml",
+			Error: "*errors.errorString",
+		},
 	}
 
 	for _, tCase := range tMatrix {
@@ -105,18 +193,71 @@ func TestInvalidConfig(t *testing.T) {
 	}
 }
 
+func TestLogLevelNotChangedByInvalidConfig(t *testing.T) {
+	t.Cleanup(func() {
+		err := setLogLevel(DEFAULT_LOG_LEVEL)
+		if err != nil {
+			t.Fatalf("Failed to cleanup after test: %v", err)
+		}
+	})
+	require.NoError(t, setLogLevel("error"), "Should set log level")
+
+	_, err := LoadConfig("testdata/invalid-config-valkey.yaml", false)
+
+	assert.Error(t, err, "Should not load invalid config")
+	assert.Equal(t, slog.LevelError, logLevel.Level(), "Should not change the log level")
+}
+
+func TestLogLevelOnlyChangedWhenApplied(t *testing.T) {
+	t.Cleanup(func() {
+		err := setLogLevel(DEFAULT_LOG_LEVEL)
+		if err != nil {
+			t.Fatalf("Failed to cleanup after test: %v", err)
+		}
+	})
+	require.NoError(t, setLogLevel("error"), "Should set log level")
+
+	cfg, err := LoadConfig("testdata/valid-config.yaml", false)
+	require.NoError(t, err, "Should load config")
+	assert.Equal(t, slog.LevelError, logLevel.Level(), "Should not change the log level when loading")
+
+	cfg.ApplyLogLevel()
+	assert.Equal(t, slog.LevelDebug, logLevel.Level(), "Should change the log level when applied")
+}
+
 func TestEnvSubstitution(t *testing.T) {
 	assert := assert.New(t)
 
 	c := Config{
 		LogLevel: "debug",
 		Valkey: failoverclient.ValkeyConfig{
-			VirtualAddress: "10.8.0.10",
-			Port:           6380,
-			Nodes:          []string{"10.8.0.11", "10.8.0.12"},
-			Username:       "testuser",
-			Password:       "testpassword",
-			TLS:            true,
+			VirtualAddress:  "10.8.0.10",
+			Port:            6380,
+			Nodes:           []failoverclient.NodeConfig{{Address: "10.8.0.11"}, {Address: "10.8.0.12"}},
+			Username:        "testuser",
+			Password:        "testpassword",
+			TLS:             failoverclient.TLSConfig{Enabled: true},
+			CheckInterval:   failoverclient.DEFAULT_CHECK_INTERVAL,
+			Timeout:         failoverclient.DEFAULT_TIMEOUT,
+			RoleCacheTTL:    failoverclient.DEFAULT_ROLE_CACHE_TTL,
+			LinkDownTimeout: failoverclient.DEFAULT_LINK_DOWN_TIMEOUT,
+			Fencing: failoverclient.FencingConfig{
+				PauseTimeout: failoverclient.DEFAULT_FENCING_PAUSE_TIMEOUT,
+			},
+			PauseTimeout: failoverclient.DEFAULT_PAUSE_TIMEOUT,
+			Dampening: failoverclient.DampeningConfig{
+				Window: failoverclient.DEFAULT_DAMPENING_WINDOW,
+			},
+		},
+		Server: server.Config{
+			Port: server.DEFAULT_PORT,
+		},
+		Notify: notify.Config{
+			Socket: notify.DEFAULT_SOCKET,
+		},
+		Sentinel: sentinel.Config{
+			Port:       sentinel.DEFAULT_PORT,
+			MasterName: sentinel.DEFAULT_MASTER_NAME,
 		},
 	}
 	t.Setenv("TESTUSERNAME", c.Valkey.Username)
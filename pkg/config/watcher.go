@@ -0,0 +1,99 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"log/slog"
+	"os"
+	"time"
+)
+
+const (
+	DEFAULT_WATCH_INTERVAL = time.Second
+	DEFAULT_WATCH_DEBOUNCE = 2 * time.Second
+)
+
+// Watches a config file for changes by polling its content.
+// Polling the content instead of using filesystem events ensures that
+// symlink swaps, as done by kubernetes for mounted ConfigMaps, are detected.
+type Watcher struct {
+	path     string
+	interval time.Duration
+	debounce time.Duration
+	onChange func()
+
+	current []byte
+	pending []byte
+	since   time.Time
+}
+
+// Create a new watcher for the given path.
+// Calls onChange once the file changed and did not change again for the debounce duration.
+func NewWatcher(path string, onChange func()) *Watcher {
+	if path == "" {
+		path = DEFAULT_CONFIG_PATH
+	}
+	return &Watcher{
+		path:     path,
+		interval: DEFAULT_WATCH_INTERVAL,
+		debounce: DEFAULT_WATCH_DEBOUNCE,
+		onChange: onChange,
+	}
+}
+
+// Watch the file until the context is cancelled
+func (w *Watcher) Run(ctx context.Context) {
+	w.current, _ = w.checksum()
+
+	slog.Info("Watching config file for changes", slog.String("path", w.path))
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(w.interval):
+		}
+		w.check()
+	}
+}
+
+// Check the file for changes and call onChange when the change has settled
+func (w *Watcher) check() {
+	sum, err := w.checksum()
+	if err != nil {
+		// The file might be in the middle of being replaced, try again on the next check
+		slog.Debug("Failed to read config file", slog.String("path", w.path), "err", err)
+		return
+	}
+
+	if bytes.Equal(sum, w.current) {
+		w.pending = nil
+		return
+	}
+
+	if !bytes.Equal(sum, w.pending) {
+		w.pending = sum
+		w.since = time.Now()
+		return
+	}
+
+	if time.Since(w.since) < w.debounce {
+		return
+	}
+
+	slog.Info("Detected change in config file", slog.String("path", w.path))
+	w.current = sum
+	w.pending = nil
+	w.onChange()
+}
+
+// Return the checksum of the file content
+func (w *Watcher) checksum() ([]byte, error) {
+	// #nosec G304: Local users can decide on the config file path freely.
+	f, err := os.ReadFile(w.path)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(f)
+	return sum[:], nil
+}
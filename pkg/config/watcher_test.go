@@ -0,0 +1,100 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewWatcher(t *testing.T) {
+	assert := assert.New(t)
+
+	w := NewWatcher("", func() {})
+
+	assert.Equal(DEFAULT_CONFIG_PATH, w.path, "Should use default config path")
+	assert.Equal(DEFAULT_WATCH_INTERVAL, w.interval, "Should use default interval")
+	assert.Equal(DEFAULT_WATCH_DEBOUNCE, w.debounce, "Should use default debounce")
+}
+
+func TestWatcherCheck(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(os.WriteFile(path, []byte("version: 1"), 0600), "Should write config file")
+
+	var calls int
+	w := NewWatcher(path, func() { calls++ })
+	w.debounce = 0
+	w.current, _ = w.checksum()
+
+	w.check()
+	assert.Equal(0, calls, "Should not call onChange when nothing changed")
+
+	require.NoError(os.WriteFile(path, []byte("version: 2"), 0600), "Should update config file")
+	w.check()
+	assert.Equal(0, calls, "Should wait for the change to settle")
+	w.check()
+	assert.Equal(1, calls, "Should call onChange once the change settled")
+	w.check()
+	assert.Equal(1, calls, "Should only call onChange once per change")
+
+	require.NoError(os.Remove(path), "Should remove config file")
+	w.check()
+	assert.Equal(1, calls, "Should ignore missing files")
+}
+
+func TestWatcherDebounce(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(os.WriteFile(path, []byte("version: 1"), 0600), "Should write config file")
+
+	var calls int
+	w := NewWatcher(path, func() { calls++ })
+	w.debounce = time.Hour
+	w.current, _ = w.checksum()
+
+	require.NoError(os.WriteFile(path, []byte("version: 2"), 0600), "Should update config file")
+	w.check()
+	w.check()
+	assert.Equal(0, calls, "Should not call onChange before the debounce duration passed")
+
+	w.since = time.Now().Add(-2 * time.Hour)
+	w.check()
+	assert.Equal(1, calls, "Should call onChange after the debounce duration passed")
+}
+
+func TestWatcherSymlinkSwap(t *testing.T) {
+	require := require.New(t)
+
+	// Mimic the layout kubernetes uses for mounted ConfigMaps
+	dir := t.TempDir()
+	require.NoError(os.Mkdir(filepath.Join(dir, "v1"), 0700))
+	require.NoError(os.Mkdir(filepath.Join(dir, "v2"), 0700))
+	require.NoError(os.WriteFile(filepath.Join(dir, "v1", "config.yaml"), []byte("version: 1"), 0600))
+	require.NoError(os.WriteFile(filepath.Join(dir, "v2", "config.yaml"), []byte("version: 2"), 0600))
+	require.NoError(os.Symlink("v1", filepath.Join(dir, "..data")))
+	require.NoError(os.Symlink(filepath.Join("..data", "config.yaml"), filepath.Join(dir, "config.yaml")))
+
+	var calls atomic.Int32
+	w := NewWatcher(filepath.Join(dir, "config.yaml"), func() { calls.Add(1) })
+	w.interval = 10 * time.Millisecond
+	w.debounce = 20 * time.Millisecond
+
+	go w.Run(t.Context())
+	time.Sleep(50 * time.Millisecond)
+
+	require.NoError(os.Symlink("v2", filepath.Join(dir, "..data_tmp")))
+	require.NoError(os.Rename(filepath.Join(dir, "..data_tmp"), filepath.Join(dir, "..data")))
+
+	assert.Eventually(t, func() bool {
+		return calls.Load() == 1
+	}, 5*time.Second, 10*time.Millisecond, "Should detect the symlink swap")
+}
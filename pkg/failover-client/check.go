@@ -0,0 +1,93 @@
+package failoverclient
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	valkeyinfo "github.com/heathcliff26/valkey-keepalived/pkg/valkey-info"
+)
+
+// The conditions a node needs to fulfill to be considered healthy
+type CheckOptions struct {
+	// The name or address of the node.
+	// Nodes that are not part of the config are reached with the global connection settings.
+	Node string
+	// The minimum number of keys the node needs to hold
+	MinKeys int64
+	// The minimum replication offset the node needs to have reached
+	MinOffset int64
+	// Fail when the node is a slave with the replication link down
+	RequireLinkUp bool
+}
+
+// The state of a node that passed the health check
+type CheckResult struct {
+	Node   string
+	Role   string
+	Keys   int64
+	Offset int64
+}
+
+// Connect once to the given node and check if it is healthy enough to hold the virtual address.
+// Returns an error describing the first failed condition.
+func CheckNode(cfg ValkeyConfig, opts CheckOptions, timeout time.Duration) (CheckResult, error) {
+	nc := NodeConfig{Address: opts.Node}
+	if i := cfg.NodeIndex(opts.Node); i >= 0 {
+		nc = cfg.Nodes[i]
+	}
+
+	option, err := newClientOption(nc.connection(cfg))
+	if err != nil {
+		return CheckResult{}, err
+	}
+	host, port := nc.HostAndPort(cfg.Port)
+	n := &node{
+		address: host,
+		port:    port,
+		option:  option,
+		name:    nc.DisplayName(cfg.Port),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	err = n.connect(ctx)
+	if err != nil {
+		return CheckResult{}, fmt.Errorf("node %s is not reachable: %w", n.name, err)
+	}
+	defer n.close()
+
+	res, err := n.client.Do(ctx, n.client.B().Info().Section(valkeyinfo.SectionPersistence, valkeyinfo.SectionReplication, valkeyinfo.SectionKeyspace).Build()).ToString()
+	if err != nil {
+		return CheckResult{}, fmt.Errorf("failed to retrieve info from node %s: %w", n.name, err)
+	}
+	info := valkeyinfo.Parse(res)
+
+	result := CheckResult{
+		Node:   n.name,
+		Role:   info.Replication.Role,
+		Keys:   info.Keys(),
+		Offset: info.Replication.Offset(),
+	}
+
+	if info.Persistence.Loading {
+		return result, fmt.Errorf("node %s is loading its dataset", n.name)
+	}
+	if info.Replication.Role == slave {
+		if info.Replication.MasterSyncInProgress {
+			return result, fmt.Errorf("node %s is syncing with its master", n.name)
+		}
+		if opts.RequireLinkUp && info.Replication.MasterLinkStatus != linkStatusUp {
+			return result, fmt.Errorf("replication link of node %s is down", n.name)
+		}
+	}
+	if result.Keys < opts.MinKeys {
+		return result, fmt.Errorf("node %s holds %d keys, needs at least %d", n.name, result.Keys, opts.MinKeys)
+	}
+	if result.Offset < opts.MinOffset {
+		return result, fmt.Errorf("node %s is at replication offset %d, needs at least %d", n.name, result.Offset, opts.MinOffset)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,108 @@
+package failoverclient
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckNode(t *testing.T) {
+	tMatrix := map[string]struct {
+		setup func(f *fakeValkey)
+		opts  CheckOptions
+		err   string
+	}{
+		"HealthyMaster": {
+			setup: func(f *fakeValkey) {
+				f.setDataset(10, 100)
+			},
+		},
+		"HealthySlaveLinkDown": {
+			setup: func(f *fakeValkey) {
+				f.setSlaveOf(&node{address: "master", port: 6379}, linkStatusDown)
+			},
+		},
+		"Loading": {
+			setup: func(f *fakeValkey) {
+				f.setLoading()
+			},
+			err: "is loading its dataset",
+		},
+		"Syncing": {
+			setup: func(f *fakeValkey) {
+				f.setSlaveOf(&node{address: "master", port: 6379}, linkStatusDown)
+				f.setSyncing()
+			},
+			err: "is syncing with its master",
+		},
+		"RequireLinkUp": {
+			setup: func(f *fakeValkey) {
+				f.setSlaveOf(&node{address: "master", port: 6379}, linkStatusDown)
+			},
+			opts: CheckOptions{RequireLinkUp: true},
+			err:  "replication link of node",
+		},
+		"RequireLinkUpHealthy": {
+			setup: func(f *fakeValkey) {
+				f.setSlaveOf(&node{address: "master", port: 6379}, linkStatusUp)
+			},
+			opts: CheckOptions{RequireLinkUp: true},
+		},
+		"MinKeys": {
+			setup: func(f *fakeValkey) {
+				f.setDataset(5, 100)
+			},
+			opts: CheckOptions{MinKeys: 10},
+			err:  "holds 5 keys, needs at least 10",
+		},
+		"MinOffset": {
+			setup: func(f *fakeValkey) {
+				f.setDataset(5, 100)
+			},
+			opts: CheckOptions{MinKeys: 5, MinOffset: 200},
+			err:  "is at replication offset 100, needs at least 200",
+		},
+	}
+
+	for name, tCase := range tMatrix {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			f := newFakeValkey(t, "runid1")
+			tCase.setup(f)
+			cfg := newTestConfig(f.mr.Addr())
+			cfg.Nodes[0].Name = "local"
+
+			opts := tCase.opts
+			opts.Node = "local"
+			res, err := CheckNode(cfg, opts, DEFAULT_TIMEOUT)
+			if tCase.err != "" {
+				assert.ErrorContains(err, tCase.err, "Should fail the check")
+				return
+			}
+			assert.NoError(err, "Should pass the check")
+			assert.Equal("local", res.Node, "Should use the name of the node")
+		})
+	}
+}
+
+func TestCheckNodeLookup(t *testing.T) {
+	assert := assert.New(t)
+
+	f := newFakeValkey(t, "runid1")
+	f.setDataset(3, 42)
+	cfg := newTestConfig("other", f.mr.Addr())
+
+	res, err := CheckNode(cfg, CheckOptions{Node: f.mr.Addr()}, DEFAULT_TIMEOUT)
+	assert.NoError(err, "Should find the node by address")
+	assert.Equal(CheckResult{Node: f.mr.Addr(), Role: master, Keys: 3, Offset: 42}, res, "Should return the state of the node")
+
+	res, err = CheckNode(newTestConfig("other"), CheckOptions{Node: f.mr.Addr()}, DEFAULT_TIMEOUT)
+	assert.NoError(err, "Should connect to nodes not part of the config")
+	assert.Equal(f.mr.Addr(), res.Node, "Should use the address as name")
+
+	addr := f.mr.Addr()
+	f.mr.Close()
+	_, err = CheckNode(cfg, CheckOptions{Node: addr}, DEFAULT_TIMEOUT)
+	assert.ErrorContains(err, "is not reachable", "Should fail when the node is down")
+}
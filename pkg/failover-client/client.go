@@ -2,7 +2,6 @@ package failoverclient
 
 import (
 	"context"
-	"crypto/tls"
 	"log/slog"
 	"os"
 	"os/signal"
@@ -10,9 +9,13 @@ import (
 	"syscall"
 	"time"
 
+	valkeyinfo "github.com/heathcliff26/valkey-keepalived/pkg/valkey-info"
 	"github.com/valkey-io/valkey-go"
 )
 
+// The number of check intervals without a completed iteration before the client is considered unhealthy
+const maxMissedIntervals = 10
+
 type FailoverClient struct {
 	clientOption   valkey.ClientOption
 	nodes          []*node
@@ -20,43 +23,57 @@ type FailoverClient struct {
 	port           int64
 	currentMaster  string
 	masterNode     *node
+	interval       time.Duration
+	timeout        time.Duration
 
-	quit chan os.Signal
-}
+	// The currently applied configuration
+	cfg          ValkeyConfig
+	configLoader ConfigLoader
 
-// Create a new failover client from the given configuration
-func NewFailoverClient(cfg ValkeyConfig) *FailoverClient {
-	option := valkey.ClientOption{
-		Username:     cfg.Username,
-		Password:     cfg.Password,
-		DisableCache: true,
-		DisableRetry: true,
-	}
-	if cfg.TLS {
-		option.TLSConfig = &tls.Config{
-			MinVersion: tls.VersionTLS12,
-		}
-	}
+	quit        chan os.Signal
+	reload      chan os.Signal
+	maintenance chan os.Signal
+	trigger     chan struct{}
 
-	nodes := make([]*node, len(cfg.Nodes))
+	// The promotion currently refused by the empty node guard
+	blockedPromotion *BlockedPromotion
+	// The new master waiting to be stable before switching to it
+	pendingSwitchover *PendingSwitchover
+	// The times of the recent switchovers and if the limit is reached
+	switchovers       []time.Time
+	switchoverLimited bool
+	// The drained node the virtual address is pointing to
+	drainedMaster *node
+	// The last switch of the master behind the virtual address
+	lastSwitchover *Switchover
+	// The last master confirmed to be promoted, clients following the master are only pointed to it
+	promotedMaster *node
+	// When the pending differences were last logged in dry-run mode
+	lastDryRunSummary time.Time
 
-	for i, addr := range cfg.Nodes {
-		host, port := extractPortFromAddress(addr, cfg.Port)
-		nodes[i] = &node{
-			address:   host,
-			port:      port,
-			up:        true,
-			roleCache: &roleCache{},
-		}
-	}
+	lock       sync.RWMutex
+	status     Status
+	splitBrain SplitBrain
+	// Set while the reconciliation is paused
+	pause        *Pause
+	pauseTimeout time.Duration
+	// Called after an iteration switched to a new master
+	switchoverHooks []func(from, to MasterState)
+}
 
-	return &FailoverClient{
-		clientOption:   option,
-		nodes:          nodes,
-		virtualAddress: cfg.VirtualAddress,
-		port:           cfg.Port,
-		quit:           make(chan os.Signal, 1),
+// Create a new failover client from the given configuration
+func NewFailoverClient(cfg ValkeyConfig) (*FailoverClient, error) {
+	c := &FailoverClient{
+		quit:        make(chan os.Signal, 1),
+		reload:      make(chan os.Signal, 1),
+		maintenance: make(chan os.Signal, 1),
+		trigger:     make(chan struct{}, 1),
 	}
+	err := c.applyConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
 }
 
 // Run the given function on all nodes in parallel and wait
@@ -81,89 +98,180 @@ func (c *FailoverClient) parallelJob(timeout time.Duration, f func(context.Conte
 
 // Check the current status of all nodes
 func (c *FailoverClient) updateNodes() {
-	c.parallelJob(time.Second, func(ctx context.Context, n *node) {
+	c.parallelJob(c.timeout, func(ctx context.Context, n *node) {
+		n.replicationInfo = nil
 		if n.client == nil {
-			err := n.connect(ctx, c.clientOption)
+			err := n.connect(ctx)
 			if err != nil {
+				n.setError(err)
 				logLevel := slog.LevelDebug
 				if n.up {
 					logLevel = slog.LevelWarn
 					n.up = false
 				}
-				slog.Log(ctx, logLevel, nodeDownMsg, slog.String("node", n.address), "err", err)
+				slog.Log(ctx, logLevel, nodeDownMsg, slog.String("node", n.name), "err", err)
+				return
 			}
-			return
+		} else {
+			n.ping(ctx)
 		}
 
-		n.ping(ctx)
+		// Retrieved once per check and shared by the offset tracking and the split-brain detection
+		if n.client != nil {
+			n.updateReplicationInfo(ctx)
+		}
 	})
 }
 
 // Continuosly check the current status and failover if necessary
 func (c *FailoverClient) Run() {
 	signal.Notify(c.quit, os.Interrupt, syscall.SIGTERM)
+	signal.Notify(c.reload, syscall.SIGHUP)
+	signal.Notify(c.maintenance, syscall.SIGUSR1, syscall.SIGUSR2)
 
 	firstTime := true
+	c.recordIteration(false)
 
 	slog.Info("Starting failover client")
+	if c.cfg.DryRun {
+		slog.Warn("Running in dry-run mode, the nodes will not be changed")
+	}
 	for {
 		if !firstTime {
 			select {
 			case <-c.quit:
 				slog.Info("Shutting down failover client")
 				return
-			case <-time.After(time.Second):
+			case <-c.reload:
+				c.reloadConfig()
+			case sig := <-c.maintenance:
+				c.handleMaintenanceSignal(sig)
+			case <-c.trigger:
+				slog.Debug("Checking immediately due to external trigger")
+			case <-time.After(c.interval):
 			}
 		} else {
 			firstTime = false
 		}
 
-		c.updateNodes()
+		ready := c.reconcile()
+		c.detectSplitBrain()
+		c.summarizeDryRun()
+		c.recordIteration(ready)
+	}
+}
 
-		client, err := newValkeyClient(c.virtualAddress, c.port, c.clientOption)
-		if err != nil {
-			slog.Error("Failed to connect to virtual address", slog.String("addr", c.virtualAddress), "err", err)
-			continue
-		}
+// Trigger an immediate check, e.g. when keepalived moved the virtual address.
+// Does nothing if a check is already pending.
+func (c *FailoverClient) Trigger() {
+	select {
+	case c.trigger <- struct{}{}:
+	default:
+	}
+}
+
+// Check the current status once and failover if necessary.
+// Returns true if the master behind the virtual address could be resolved to a known node.
+func (c *FailoverClient) reconcile() bool {
+	c.updateNodes()
+	c.updateNodeMetrics()
+
+	client, err := newValkeyClient(c.virtualAddress, c.port, c.clientOption)
+	if err != nil {
+		virtualAddressErrorsTotal.WithLabelValues(vaErrorConnect).Inc()
+		slog.Error("Failed to connect to virtual address", slog.String("addr", c.virtualAddress), "err", err)
+		return false
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
 
-		res, err := client.Do(context.Background(), client.B().Info().Section("server").Build()).ToString()
-		client.Close()
-		if err != nil {
-			slog.Error("Failed to retrieve info from virtual address", slog.String("addr", c.virtualAddress), "err", err)
-			continue
+	res, err := client.Do(ctx, client.B().Info().Section(valkeyinfo.SectionServer).Build()).ToString()
+	client.Close()
+	if err != nil {
+		virtualAddressErrorsTotal.WithLabelValues(vaErrorInfo).Inc()
+		slog.Error("Failed to retrieve info from virtual address", slog.String("addr", c.virtualAddress), "err", err)
+		return false
+	}
+	currentMaster := valkeyinfo.Parse(res).Server.RunID
+	newMaster := c.nodeByRunID(currentMaster)
+	if newMaster != nil && newMaster.drained {
+		virtualAddressErrorsTotal.WithLabelValues(vaErrorDrainedMaster).Inc()
+		c.setDrainedMaster(newMaster)
+		return false
+	}
+	c.setDrainedMaster(nil)
+
+	// Check before switching over, the switchover is picked up once resumed
+	if c.reconciliationPaused() {
+		if newMaster == nil {
+			virtualAddressErrorsTotal.WithLabelValues(vaErrorUnknownMaster).Inc()
+			slog.Error("Could not find the current masters addr", slog.String(runID, currentMaster))
+			return false
 		}
-		currentMaster := ParseValueFromInfo(res, runID)
 		if currentMaster != c.currentMaster {
-			found := false
-			for _, n := range c.nodes {
-				if n.runID == currentMaster {
-					c.currentMaster = currentMaster
-					c.masterNode = n
-					found = true
-				}
-			}
-			if !found {
-				slog.Error("Could not find the current masters addr", slog.String(runID, currentMaster))
-				continue
-			} else {
-				slog.Info("Switching over to new master", slog.String("addr", c.masterNode.address), slog.Int64("port", c.masterNode.port), slog.String(runID, c.currentMaster))
-			}
+			slog.Info("Reconciliation is paused, not switching over to the master behind the virtual address", slog.String(runID, currentMaster))
+		} else {
+			slog.Debug("Reconciliation is paused, not changing the nodes")
 		}
+		return true
+	}
 
-		c.parallelJob(time.Second, func(ctx context.Context, n *node) {
-			if n.runID == c.currentMaster {
-				err := n.master(ctx)
-				if err != nil {
-					slog.Error("Failed to update node to master", slog.String("node", n.address), "err", err)
-				}
-			} else {
-				err := n.slave(ctx, c.masterNode)
-				if err != nil {
-					slog.Error("Failed to update node to slave", slog.String("node", n.address), "err", err)
-				}
+	if currentMaster != c.currentMaster {
+		if newMaster == nil {
+			virtualAddressErrorsTotal.WithLabelValues(vaErrorUnknownMaster).Inc()
+			slog.Error("Could not find the current masters addr", slog.String(runID, currentMaster))
+			return false
+		}
+		if !c.dampenSwitchover(newMaster) || !c.checkPromotion(newMaster) {
+			return false
+		}
+
+		oldMaster := c.masterNode
+		c.currentMaster = currentMaster
+		c.masterNode = newMaster
+		slog.Info("Switching over to new master", slog.String("addr", c.masterNode.address), slog.Int64("port", c.masterNode.port), slog.String(runID, c.currentMaster))
+		failoversTotal.Inc()
+		c.recordSwitchover(oldMaster, newMaster)
+		c.countSwitchover(oldMaster)
+		c.updateMasterMetrics()
+	} else {
+		// The virtual address moved back to the current master
+		c.cancelSwitchover()
+		if c.blockedPromotion != nil {
+			c.setBlockedPromotion(nil)
+		}
+	}
+
+	// Only written by the job of the master
+	promoted := false
+	c.parallelJob(c.timeout, func(ctx context.Context, n *node) {
+		if n.drained {
+			slog.Debug("Node is drained, skipping for update", slog.String("node", n.name))
+			return
+		}
+		if n.runID == c.currentMaster {
+			err := n.master(ctx)
+			if err != nil {
+				n.setError(err)
+				slog.Error("Failed to update node to master", slog.String("node", n.name), "err", err)
+				return
 			}
-		})
+			// In dry-run mode master succeeds without promoting the node
+			promoted = n.roleCache.ConfirmsMaster()
+		} else {
+			err := n.slave(ctx, c.masterNode)
+			if err != nil {
+				n.setError(err)
+				slog.Error("Failed to update node to slave", slog.String("node", n.name), "err", err)
+			}
+		}
+	})
+	if promoted {
+		c.promotedMaster = c.masterNode
 	}
+
+	return true
 }
 
 // Close all open client connections
@@ -3,11 +3,13 @@ package failoverclient
 import (
 	"context"
 	"fmt"
+	"os"
 	"syscall"
 	"testing"
 	"time"
 
 	"github.com/alicebob/miniredis/v2"
+	valkeyinfo "github.com/heathcliff26/valkey-keepalived/pkg/valkey-info"
 	testutils "github.com/heathcliff26/valkey-keepalived/tests/utils"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -26,25 +28,67 @@ func TestNewFailoverClient(t *testing.T) {
 	cfg := ValkeyConfig{
 		VirtualAddress: "VAddress",
 		Port:           6379,
-		Nodes:          []string{"node1:6380", "node2"},
+		Nodes:          []NodeConfig{{Address: "node1:6380"}, {Address: "node2"}},
 		Username:       "user",
 		Password:       "pass",
-		TLS:            true,
+		TLS:            TLSConfig{Enabled: true},
+		CheckInterval:  2 * time.Second,
+		Timeout:        3 * time.Second,
+		RoleCacheTTL:   time.Hour,
 	}
 
-	client := NewFailoverClient(cfg)
+	client, err := NewFailoverClient(cfg)
+	require.NoError(err, "Should create client")
 
 	assert.Equal(cfg.VirtualAddress, client.virtualAddress, "Virtual address should be set")
 	assert.Equal(cfg.Port, client.port, "Port should be set")
 	assert.Equal(cfg.Username, client.clientOption.Username, "Username should be set")
 	assert.Equal(cfg.Password, client.clientOption.Password, "Password should be set")
 	assert.NotNil(client.clientOption.TLSConfig, "TLS config should be set")
+	assert.Equal(cfg.CheckInterval, client.interval, "Check interval should be set")
+	assert.Equal(cfg.Timeout, client.timeout, "Timeout should be set")
 
 	require.Equal(len(cfg.Nodes), len(client.nodes), "Should have correct number of nodes")
 	assert.Equal("node1", client.nodes[0].address, "Node 1 address should be set correctly")
 	assert.Equal(int64(6380), client.nodes[0].port, "Node 1 port should be set correctly")
 	assert.Equal("node2", client.nodes[1].address, "Node 2 address should be set correctly")
 	assert.Equal(int64(6379), client.nodes[1].port, "Node 2 port should be set to default")
+	assert.Equal(cfg.RoleCacheTTL, client.nodes[0].roleCache.ttl, "Role cache ttl should be set")
+	assert.Equal("node1:6380", client.nodes[0].name, "Node name should default to the address")
+	assert.Equal(cfg.Username, client.nodes[0].option.Username, "Node should use global username")
+	assert.NotNil(client.nodes[0].option.TLSConfig, "Node should use global tls config")
+}
+
+func TestNewFailoverClientPerNodeSettings(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	cfg := ValkeyConfig{
+		VirtualAddress: "VAddress",
+		Port:           6379,
+		Nodes: []NodeConfig{
+			{Address: "node1"},
+			{Name: "second", Address: "node2", Port: 6380, Username: "node2user", Password: "node2pass", TLS: &TLSConfig{}},
+		},
+		Username:      "user",
+		Password:      "pass",
+		TLS:           TLSConfig{Enabled: true},
+		CheckInterval: DEFAULT_CHECK_INTERVAL,
+		Timeout:       DEFAULT_TIMEOUT,
+	}
+
+	client, err := NewFailoverClient(cfg)
+	require.NoError(err, "Should create client")
+
+	require.Len(client.nodes, 2, "Should have correct number of nodes")
+	assert.Equal("user", client.nodes[0].option.Username, "Node 1 should use global username")
+	assert.NotNil(client.nodes[0].option.TLSConfig, "Node 1 should use global tls config")
+
+	assert.Equal("second", client.nodes[1].name, "Node 2 should have a name")
+	assert.Equal(int64(6380), client.nodes[1].port, "Node 2 should use its own port")
+	assert.Equal("node2user", client.nodes[1].option.Username, "Node 2 should use its own username")
+	assert.Equal("node2pass", client.nodes[1].option.Password, "Node 2 should use its own password")
+	assert.Nil(client.nodes[1].option.TLSConfig, "Node 2 should disable tls")
 }
 
 func TestClientBasicFailover(t *testing.T) {
@@ -180,6 +224,38 @@ func TestClientClose(t *testing.T) {
 	}
 }
 
+func TestTrigger(t *testing.T) {
+	assert := assert.New(t)
+
+	c, _ := newFakeCluster(t, ValkeyConfig{}, "runid1")
+	c.interval = time.Hour
+	c.quit = make(chan os.Signal, 1)
+	c.reload = make(chan os.Signal, 1)
+	c.maintenance = make(chan os.Signal, 1)
+	c.trigger = make(chan struct{}, 1)
+
+	done := make(chan struct{})
+	go func() {
+		c.Run()
+		close(done)
+	}()
+	t.Cleanup(func() {
+		c.quit <- syscall.SIGTERM
+		<-done
+	})
+
+	assert.Eventually(c.Ready, time.Second, 10*time.Millisecond, "Should complete the first iteration")
+	first := c.Status().LastIteration
+
+	assert.NotPanics(func() {
+		c.Trigger()
+		c.Trigger()
+	}, "Should not block when a check is already pending")
+	assert.Eventually(func() bool {
+		return c.Status().LastIteration.After(first)
+	}, time.Second, 10*time.Millisecond, "Should check immediately when triggered")
+}
+
 // Create a new test setup and failoverclient.
 // Skip test if no container runtime is found.
 // Ensure cleanup is called for the setup.
@@ -195,20 +271,25 @@ func newSetupAndClient(t *testing.T, prefix string, nodeCount int) (*testutils.F
 	cfg := ValkeyConfig{
 		VirtualAddress: setup.Address,
 		Port:           int64(setup.Port),
-		Nodes:          make([]string, len(setup.Nodes)),
+		Nodes:          make([]NodeConfig, len(setup.Nodes)),
+		CheckInterval:  DEFAULT_CHECK_INTERVAL,
+		Timeout:        DEFAULT_TIMEOUT,
+		RoleCacheTTL:   DEFAULT_ROLE_CACHE_TTL,
 	}
 	for i, node := range setup.Nodes {
-		cfg.Nodes[i] = fmt.Sprintf("%s:%d", setup.Address, node.Port)
+		cfg.Nodes[i] = NodeConfig{Address: fmt.Sprintf("%s:%d", setup.Address, node.Port)}
 	}
-	return setup, NewFailoverClient(cfg)
+	c, err := NewFailoverClient(cfg)
+	require.NoError(t, err, "Should create client")
+	return setup, c
 }
 
-func getRoleOfNode(ctx context.Context, n *node) (string, string, error) {
-	res, err := n.getReplicationInfo(ctx)
+func getRoleOfNode(ctx context.Context, n *node) (string, valkeyinfo.Replication, error) {
+	repl, err := n.getReplicationInfo(ctx)
 	if err != nil {
-		return "", "", err
+		return "", valkeyinfo.Replication{}, err
 	}
-	return ParseValueFromInfo(res, role), res, nil
+	return repl.Role, repl, nil
 }
 
 func assertNodeDown(t *testing.T, n *node, id int) {
@@ -218,7 +299,7 @@ func assertNodeDown(t *testing.T, n *node, id int) {
 
 func assertNodeRoleEventually(t *testing.T, ctx context.Context, n *node, expectedRole string, masterNode *node, id int) {
 	assert.Eventuallyf(t, func() bool {
-		role, info, err := getRoleOfNode(ctx, n)
+		role, repl, err := getRoleOfNode(ctx, n)
 		if err != nil {
 			t.Logf("Failed to get role of node %d: %v", id, err)
 			return false
@@ -227,10 +308,102 @@ func assertNodeRoleEventually(t *testing.T, ctx context.Context, n *node, expect
 		if role != expectedRole {
 			return false
 		}
-		if masterNode != nil && !infoSlaveOfNode(info, masterNode) {
-			t.Logf("Node %d has the wrong master, expected \"%s:%d\" but has \"%s:%s\"", id, masterNode.address, masterNode.port, ParseValueFromInfo(info, masterHost), ParseValueFromInfo(info, masterPort))
+		if masterNode != nil && !infoSlaveOfNode(repl, masterNode) {
+			t.Logf("Node %d has the wrong master, expected \"%s:%d\" but has \"%s:%d\"", id, masterNode.address, masterNode.port, repl.MasterHost, repl.MasterPort)
 			return false
 		}
 		return true
 	}, waitTimeout, checkIntervall, "Node %d should have the expected role %s", id, expectedRole)
 }
+
+func TestReconcilePromotedMaster(t *testing.T) {
+	t.Run("Promoted", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		c, fakes := newFakeCluster(t, ValkeyConfig{}, "runid1", "runid2")
+		fakes[1].setSlaveOf(c.nodes[0], linkStatusUp)
+		var switchovers []MasterState
+		c.OnSwitchover(func(_, to MasterState) {
+			switchovers = append(switchovers, to)
+		})
+		require.True(c.reconcile(), "Should reconcile the initial master")
+		c.recordIteration(true)
+		assert.Same(c.nodes[0], c.promotedMaster, "Should confirm the master that already is master")
+
+		c.port = c.nodes[1].port
+		require.True(c.reconcile(), "Should switch over")
+		c.recordIteration(true)
+		assert.Same(c.nodes[1], c.promotedMaster, "Should confirm the promoted master")
+		assert.Len(switchovers, 1, "Should announce the switchover")
+	})
+	t.Run("DryRun", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		c, fakes := newFakeCluster(t, ValkeyConfig{DryRun: true}, "runid1", "runid2")
+		fakes[1].setSlaveOf(c.nodes[0], linkStatusUp)
+		var switchovers []MasterState
+		c.OnSwitchover(func(_, to MasterState) {
+			switchovers = append(switchovers, to)
+		})
+		require.True(c.reconcile(), "Should reconcile the initial master")
+		c.recordIteration(true)
+
+		c.port = c.nodes[1].port
+		require.True(c.reconcile(), "Should switch over")
+		c.recordIteration(true)
+
+		assert.Same(c.nodes[1], c.masterNode, "Should track the master behind the virtual address")
+		assert.Same(c.nodes[0], c.promotedMaster, "Should not confirm a master that was not promoted")
+		status := c.Status()
+		require.NotNil(status.PromotedMaster, "Should show the promoted master")
+		assert.Equal("runid1", status.PromotedMaster.RunID, "Should keep the old master")
+		assert.Empty(switchovers, "Should not announce the switchover")
+	})
+	t.Run("FailedPromotion", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		c, fakes := newFakeCluster(t, ValkeyConfig{}, "runid1", "runid2")
+		fakes[1].setSlaveOf(c.nodes[0], linkStatusUp)
+		var switchovers []MasterState
+		c.OnSwitchover(func(_, to MasterState) {
+			switchovers = append(switchovers, to)
+		})
+		require.True(c.reconcile(), "Should reconcile the initial master")
+		c.recordIteration(true)
+
+		fakes[1].setFailReplicaof()
+		c.port = c.nodes[1].port
+		require.True(c.reconcile(), "Should switch over")
+		c.recordIteration(true)
+
+		assert.Contains(fakes[1].receivedCommands(), "REPLICAOF NO ONE", "Should try to promote the new master")
+		assert.Same(c.nodes[0], c.promotedMaster, "Should not confirm a master that failed to be promoted")
+		assert.Empty(switchovers, "Should not announce the switchover")
+	})
+}
+
+func TestReconcileReplicationInfoOnce(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	c, fakes := newFakeCluster(t, ValkeyConfig{}, "runid1", "runid2", "runid3")
+	fakes[1].setSlaveOf(c.nodes[0], linkStatusUp)
+	require.True(c.reconcile(), "Should reconcile the initial master")
+
+	// Without the cached roles every node needs its replication info
+	requests := make([]int, len(fakes))
+	for i, n := range c.nodes {
+		n.roleCache.Clear()
+		requests[i] = fakes[i].replicationInfoRequests()
+	}
+	fakes[2].setSlaveOf(c.nodes[1], linkStatusUp)
+
+	assert.True(c.reconcile(), "Should reconcile the master")
+	for i, f := range fakes {
+		assert.Equalf(requests[i]+1, f.replicationInfoRequests(), "Should retrieve the replication info of node %d once per check", i)
+	}
+	assert.Equal(replicaofCmd(c.nodes[0]), fakes[2].receivedCommands()[len(fakes[2].receivedCommands())-1], "Should make the replica follow the master")
+}
@@ -1,14 +1,47 @@
 package failoverclient
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+
+	"github.com/valkey-io/valkey-go"
+	"go.yaml.in/yaml/v3"
+)
+
+const (
+	DEFAULT_CHECK_INTERVAL        = time.Second
+	DEFAULT_TIMEOUT               = time.Second
+	DEFAULT_ROLE_CACHE_TTL        = time.Minute
+	DEFAULT_LINK_DOWN_TIMEOUT     = 30 * time.Second
+	DEFAULT_FENCING_PAUSE_TIMEOUT = 10 * time.Second
+	DEFAULT_PAUSE_TIMEOUT         = time.Hour
+	DEFAULT_DAMPENING_WINDOW      = time.Hour
+)
 
 type ValkeyConfig struct {
-	VirtualAddress string   `yaml:"virtualAddress"`
-	Port           int64    `yaml:"port,omitempty"`
-	Nodes          []string `yaml:"nodes"`
-	Username       string   `yaml:"username,omitempty"`
-	Password       string   `yaml:"password,omitempty"`
-	TLS            bool     `yaml:"tls,omitempty"`
+	VirtualAddress  string        `yaml:"virtualAddress"`
+	Port            int64         `yaml:"port,omitempty"`
+	Nodes           []NodeConfig  `yaml:"nodes"`
+	Username        string        `yaml:"username,omitempty"`
+	UsernameFile    string        `yaml:"usernameFile,omitempty"`
+	Password        string        `yaml:"password,omitempty"`
+	PasswordFile    string        `yaml:"passwordFile,omitempty"`
+	TLS             TLSConfig     `yaml:"tls,omitempty"`
+	Replication     Credentials   `yaml:"replication,omitempty"`
+	CheckInterval   time.Duration `yaml:"checkInterval,omitempty"`
+	Timeout         time.Duration `yaml:"timeout,omitempty"`
+	RoleCacheTTL    time.Duration `yaml:"roleCacheTTL,omitempty"`
+	LinkDownTimeout time.Duration `yaml:"linkDownTimeout,omitempty"`
+	LatchSplitBrain bool          `yaml:"latchSplitBrain,omitempty"`
+	Fencing         FencingConfig `yaml:"fencing,omitempty"`
+	// Promote the node behind the virtual address, even when it is empty while other nodes hold data
+	AllowEmptyPromotion bool `yaml:"allowEmptyPromotion,omitempty"`
+	// Only log the changes to the nodes instead of executing them
+	DryRun bool `yaml:"dryRun,omitempty"`
+	// How long the reconciliation stays paused when no duration is given, 0 keeps it paused until resumed
+	PauseTimeout time.Duration `yaml:"pauseTimeout,omitempty"`
+	// Delay and limit switchovers when the virtual address is flapping
+	Dampening DampeningConfig `yaml:"dampening,omitempty"`
 }
 
 // Ensure that the given config is valid
@@ -22,6 +55,195 @@ func (c ValkeyConfig) Validate() error {
 	if len(c.Nodes) < 1 {
 		return fmt.Errorf("need to have at least 1 node listed")
 	}
+	seen := make(map[string]bool, len(c.Nodes))
+	for _, n := range c.Nodes {
+		err := n.Validate()
+		if err != nil {
+			return fmt.Errorf("invalid node \"%s\": %w", n.Address, err)
+		}
+		addr := n.hostPortString(c.Port)
+		if seen[addr] {
+			return fmt.Errorf("node \"%s\" is listed multiple times", addr)
+		}
+		seen[addr] = true
+	}
+	err := validateCredentials(c.Username, c.UsernameFile, c.Password, c.PasswordFile)
+	if err != nil {
+		return err
+	}
+	err = c.TLS.Validate()
+	if err != nil {
+		return err
+	}
+	err = c.Replication.Validate()
+	if err != nil {
+		return fmt.Errorf("invalid replication credentials: %w", err)
+	}
+	if c.CheckInterval <= 0 {
+		return fmt.Errorf("invalid check interval, needs to be greater than 0")
+	}
+	if c.Timeout <= 0 {
+		return fmt.Errorf("invalid timeout, needs to be greater than 0")
+	}
+	if c.RoleCacheTTL < 0 {
+		return fmt.Errorf("invalid role cache ttl, can't be negative")
+	}
+	if c.LinkDownTimeout < 0 {
+		return fmt.Errorf("invalid link down timeout, can't be negative")
+	}
+	err = c.Fencing.Validate()
+	if err != nil {
+		return err
+	}
+	if c.PauseTimeout < 0 {
+		return fmt.Errorf("invalid pause timeout, can't be negative")
+	}
+	err = c.Dampening.Validate()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Return the index of the node with the given name, address or "host:port".
+// Returns -1 when the node is not part of the config.
+func (c ValkeyConfig) NodeIndex(name string) int {
+	for i, n := range c.Nodes {
+		if n.Name == name || n.Address == name || n.hostPortString(c.Port) == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Return the settings for connecting to the virtual address
+func (c ValkeyConfig) connection() connectionConfig {
+	return connectionConfig{
+		Username:     c.Username,
+		UsernameFile: c.UsernameFile,
+		Password:     c.Password,
+		PasswordFile: c.PasswordFile,
+		TLS:          c.TLS,
+	}
+}
+
+type NodeConfig struct {
+	Name         string     `yaml:"name,omitempty"`
+	Address      string     `yaml:"address"`
+	Port         int64      `yaml:"port,omitempty"`
+	Username     string     `yaml:"username,omitempty"`
+	UsernameFile string     `yaml:"usernameFile,omitempty"`
+	Password     string     `yaml:"password,omitempty"`
+	PasswordFile string     `yaml:"passwordFile,omitempty"`
+	TLS          *TLSConfig `yaml:"tls,omitempty"`
+	// Exclude the node from the failover during maintenance
+	Drained bool `yaml:"drained,omitempty"`
+}
+
+// Support the short format, where the node is only given as "host:port"
+func (c *NodeConfig) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*c = NodeConfig{}
+		return value.Decode(&c.Address)
+	}
+
+	type plain NodeConfig
+	return value.Decode((*plain)(c))
+}
+
+// Ensure that the given config is valid
+func (c NodeConfig) Validate() error {
+	if c.Address == "" {
+		return fmt.Errorf("missing address")
+	}
+	if c.Port < 0 || c.Port > 65535 {
+		return fmt.Errorf("invalid port, needs to be between 0-65535")
+	}
+	if _, port := extractPortFromAddress(c.Address, 0); c.Port != 0 && port != 0 {
+		return fmt.Errorf("port is given as part of the address and as port, only one can be set")
+	}
+	err := validateCredentials(c.Username, c.UsernameFile, c.Password, c.PasswordFile)
+	if err != nil {
+		return err
+	}
+	if c.TLS != nil {
+		return c.TLS.Validate()
+	}
+	return nil
+}
+
+// Return the host and port of the node.
+// The port can be given as part of the address or as port, otherwise it falls back to the default.
+func (c NodeConfig) HostAndPort(defaultPort int64) (string, int64) {
+	if c.Port != 0 {
+		defaultPort = c.Port
+	}
+	return extractPortFromAddress(c.Address, defaultPort)
+}
+
+// Return the name of the node, falls back to "host:port" when no name is set
+func (c NodeConfig) DisplayName(defaultPort int64) string {
+	if c.Name != "" {
+		return c.Name
+	}
+	return c.hostPortString(defaultPort)
+}
+
+// Return the address of the node as "host:port"
+func (c NodeConfig) hostPortString(defaultPort int64) string {
+	host, port := c.HostAndPort(defaultPort)
+	return (&node{address: host, port: port}).String()
+}
 
+// Return the settings for connecting to the node.
+// Settings that are not set for the node are taken from the global config.
+func (c NodeConfig) connection(global ValkeyConfig) connectionConfig {
+	res := global.connection()
+	if c.Username != "" || c.UsernameFile != "" {
+		res.Username = c.Username
+		res.UsernameFile = c.UsernameFile
+	}
+	if c.Password != "" || c.PasswordFile != "" {
+		res.Password = c.Password
+		res.PasswordFile = c.PasswordFile
+	}
+	if c.TLS != nil {
+		res.TLS = *c.TLS
+	}
+	return res
+}
+
+type Credentials struct {
+	Username     string `yaml:"username,omitempty"`
+	UsernameFile string `yaml:"usernameFile,omitempty"`
+	Password     string `yaml:"password,omitempty"`
+	PasswordFile string `yaml:"passwordFile,omitempty"`
+}
+
+// Ensure that the given credentials are valid
+func (c Credentials) Validate() error {
+	return validateCredentials(c.Username, c.UsernameFile, c.Password, c.PasswordFile)
+}
+
+// Check if any credentials are set
+func (c Credentials) isSet() bool {
+	return c != Credentials{}
+}
+
+// Return the credentials, reading them from the files when set
+func (c Credentials) load() (valkey.AuthCredentials, error) {
+	return loadCredentials(c.Username, c.UsernameFile, c.Password, c.PasswordFile)
+}
+
+// Ensure that at most one of the inline or file variants is set.
+// Neither being set is allowed, as authentication is optional.
+func validateCredentials(username, usernameFile, password, passwordFile string) error {
+	if username != "" && usernameFile != "" {
+		return fmt.Errorf("only one of username and usernameFile can be set")
+	}
+	if password != "" && passwordFile != "" {
+		return fmt.Errorf("only one of password and passwordFile can be set")
+	}
 	return nil
 }
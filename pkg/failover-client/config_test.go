@@ -2,8 +2,11 @@ package failoverclient
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.yaml.in/yaml/v3"
 )
 
 func TestConfigValidate(t *testing.T) {
@@ -17,7 +20,10 @@ func TestConfigValidate(t *testing.T) {
 			Config: ValkeyConfig{
 				VirtualAddress: "10.8.0.10",
 				Port:           6379,
-				Nodes:          []string{"10.8.0.11", "10.8.0.12"},
+				Nodes:          []NodeConfig{{Address: "10.8.0.11"}, {Address: "10.8.0.12"}},
+				CheckInterval:  DEFAULT_CHECK_INTERVAL,
+				Timeout:        DEFAULT_TIMEOUT,
+				RoleCacheTTL:   DEFAULT_ROLE_CACHE_TTL,
 			},
 			Valid: true,
 		},
@@ -26,10 +32,26 @@ func TestConfigValidate(t *testing.T) {
 			Config: ValkeyConfig{
 				VirtualAddress: "10.8.0.10",
 				Port:           6379,
-				Nodes:          []string{"10.8.0.11", "10.8.0.12"},
+				Nodes:          []NodeConfig{{Address: "10.8.0.11"}, {Address: "10.8.0.12"}},
 				Username:       "testuser",
 				Password:       "testpassword",
-				TLS:            true,
+				TLS:            TLSConfig{Enabled: true},
+				CheckInterval:  100 * time.Millisecond,
+				Timeout:        5 * time.Second,
+				RoleCacheTTL:   0,
+			},
+			Valid: true,
+		},
+		{
+			Name: "CredentialsFromFile",
+			Config: ValkeyConfig{
+				VirtualAddress: "10.8.0.10",
+				Port:           6379,
+				Nodes:          []NodeConfig{{Address: "10.8.0.11"}, {Address: "10.8.0.12"}},
+				UsernameFile:   "/run/secrets/username",
+				PasswordFile:   "/run/secrets/password",
+				CheckInterval:  DEFAULT_CHECK_INTERVAL,
+				Timeout:        DEFAULT_TIMEOUT,
 			},
 			Valid: true,
 		},
@@ -38,7 +60,7 @@ func TestConfigValidate(t *testing.T) {
 			Config: ValkeyConfig{
 				VirtualAddress: "",
 				Port:           6379,
-				Nodes:          []string{"10.8.0.11", "10.8.0.12"},
+				Nodes:          []NodeConfig{{Address: "10.8.0.11"}, {Address: "10.8.0.12"}},
 			},
 			Valid: false,
 		},
@@ -55,7 +77,7 @@ func TestConfigValidate(t *testing.T) {
 			Config: ValkeyConfig{
 				VirtualAddress: "10.8.0.10",
 				Port:           -1,
-				Nodes:          []string{"10.8.0.11", "10.8.0.12"},
+				Nodes:          []NodeConfig{{Address: "10.8.0.11"}, {Address: "10.8.0.12"}},
 			},
 			Valid: false,
 		},
@@ -64,7 +86,91 @@ func TestConfigValidate(t *testing.T) {
 			Config: ValkeyConfig{
 				VirtualAddress: "10.8.0.10",
 				Port:           65536,
-				Nodes:          []string{"10.8.0.11", "10.8.0.12"},
+				Nodes:          []NodeConfig{{Address: "10.8.0.11"}, {Address: "10.8.0.12"}},
+			},
+			Valid: false,
+		},
+		{
+			Name: "UsernameAndUsernameFile",
+			Config: ValkeyConfig{
+				VirtualAddress: "10.8.0.10",
+				Port:           6379,
+				Nodes:          []NodeConfig{{Address: "10.8.0.11"}, {Address: "10.8.0.12"}},
+				Username:       "testuser",
+				UsernameFile:   "/run/secrets/username",
+				CheckInterval:  DEFAULT_CHECK_INTERVAL,
+				Timeout:        DEFAULT_TIMEOUT,
+			},
+			Valid: false,
+		},
+		{
+			Name: "PasswordAndPasswordFile",
+			Config: ValkeyConfig{
+				VirtualAddress: "10.8.0.10",
+				Port:           6379,
+				Nodes:          []NodeConfig{{Address: "10.8.0.11"}, {Address: "10.8.0.12"}},
+				Password:       "testpassword",
+				PasswordFile:   "/run/secrets/password",
+				CheckInterval:  DEFAULT_CHECK_INTERVAL,
+				Timeout:        DEFAULT_TIMEOUT,
+			},
+			Valid: false,
+		},
+		{
+			Name: "ReplicationCredentials",
+			Config: ValkeyConfig{
+				VirtualAddress: "10.8.0.10",
+				Port:           6379,
+				Nodes:          []NodeConfig{{Address: "10.8.0.11"}, {Address: "10.8.0.12"}},
+				Replication:    Credentials{Username: "repl", PasswordFile: "/run/secrets/repl-password"},
+				CheckInterval:  DEFAULT_CHECK_INTERVAL,
+				Timeout:        DEFAULT_TIMEOUT,
+			},
+			Valid: true,
+		},
+		{
+			Name: "InvalidReplicationCredentials",
+			Config: ValkeyConfig{
+				VirtualAddress: "10.8.0.10",
+				Port:           6379,
+				Nodes:          []NodeConfig{{Address: "10.8.0.11"}, {Address: "10.8.0.12"}},
+				Replication:    Credentials{Password: "repl", PasswordFile: "/run/secrets/repl-password"},
+				CheckInterval:  DEFAULT_CHECK_INTERVAL,
+				Timeout:        DEFAULT_TIMEOUT,
+			},
+			Valid: false,
+		},
+		{
+			Name: "MissingCheckInterval",
+			Config: ValkeyConfig{
+				VirtualAddress: "10.8.0.10",
+				Port:           6379,
+				Nodes:          []NodeConfig{{Address: "10.8.0.11"}, {Address: "10.8.0.12"}},
+				Timeout:        DEFAULT_TIMEOUT,
+				RoleCacheTTL:   DEFAULT_ROLE_CACHE_TTL,
+			},
+			Valid: false,
+		},
+		{
+			Name: "MissingTimeout",
+			Config: ValkeyConfig{
+				VirtualAddress: "10.8.0.10",
+				Port:           6379,
+				Nodes:          []NodeConfig{{Address: "10.8.0.11"}, {Address: "10.8.0.12"}},
+				CheckInterval:  DEFAULT_CHECK_INTERVAL,
+				RoleCacheTTL:   DEFAULT_ROLE_CACHE_TTL,
+			},
+			Valid: false,
+		},
+		{
+			Name: "NegativeRoleCacheTTL",
+			Config: ValkeyConfig{
+				VirtualAddress: "10.8.0.10",
+				Port:           6379,
+				Nodes:          []NodeConfig{{Address: "10.8.0.11"}, {Address: "10.8.0.12"}},
+				CheckInterval:  DEFAULT_CHECK_INTERVAL,
+				Timeout:        DEFAULT_TIMEOUT,
+				RoleCacheTTL:   -time.Second,
 			},
 			Valid: false,
 		},
@@ -82,3 +188,164 @@ func TestConfigValidate(t *testing.T) {
 		})
 	}
 }
+
+func TestNodeConfigUnmarshalYAML(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	input := `nodes:
+  - "node1:6380"
+  - name: second
+    address: node2
+    port: 6381
+    username: user
+    passwordFile: /run/secrets/password
+    tls:
+      enabled: true
+      serverName: node2.example.com
+`
+	var res struct {
+		Nodes []NodeConfig `yaml:"nodes"`
+	}
+	err := yaml.Unmarshal([]byte(input), &res)
+	require.NoError(err, "Should parse nodes")
+
+	require.Len(res.Nodes, 2, "Should parse all nodes")
+	assert.Equal(NodeConfig{Address: "node1:6380"}, res.Nodes[0], "Should parse the short format")
+	assert.Equal(NodeConfig{
+		Name:         "second",
+		Address:      "node2",
+		Port:         6381,
+		Username:     "user",
+		PasswordFile: "/run/secrets/password",
+		TLS:          &TLSConfig{Enabled: true, ServerName: "node2.example.com"},
+	}, res.Nodes[1], "Should parse the structured format")
+}
+
+func TestNodeConfigValidate(t *testing.T) {
+	tMatrix := map[string]struct {
+		cfg   NodeConfig
+		valid bool
+	}{
+		"AddressOnly": {
+			cfg:   NodeConfig{Address: "node1"},
+			valid: true,
+		},
+		"Full": {
+			cfg:   NodeConfig{Name: "node1", Address: "node1", Port: 6379, Username: "user", PasswordFile: "/password", TLS: &TLSConfig{Enabled: true}},
+			valid: true,
+		},
+		"MissingAddress": {
+			cfg:   NodeConfig{Port: 6379},
+			valid: false,
+		},
+		"InvalidPort": {
+			cfg:   NodeConfig{Address: "node1", Port: 65536},
+			valid: false,
+		},
+		"PortInAddress": {
+			cfg:   NodeConfig{Address: "node1:6380"},
+			valid: true,
+		},
+		"PortInAddressAndPort": {
+			cfg:   NodeConfig{Address: "node1:6380", Port: 6381},
+			valid: false,
+		},
+		"PasswordAndPasswordFile": {
+			cfg:   NodeConfig{Address: "node1", Password: "pass", PasswordFile: "/password"},
+			valid: false,
+		},
+		"InvalidTLS": {
+			cfg:   NodeConfig{Address: "node1", TLS: &TLSConfig{Enabled: true, CertFile: "/tls.crt"}},
+			valid: false,
+		},
+	}
+
+	for name, tCase := range tMatrix {
+		t.Run(name, func(t *testing.T) {
+			if tCase.valid {
+				assert.NoError(t, tCase.cfg.Validate())
+			} else {
+				assert.Error(t, tCase.cfg.Validate())
+			}
+		})
+	}
+}
+
+func TestValkeyConfigDuplicateNodes(t *testing.T) {
+	cfg := ValkeyConfig{
+		VirtualAddress: "10.8.0.10",
+		Port:           6379,
+		Nodes:          []NodeConfig{{Address: "10.8.0.11"}, {Address: "10.8.0.11", Port: 6379}},
+		CheckInterval:  DEFAULT_CHECK_INTERVAL,
+		Timeout:        DEFAULT_TIMEOUT,
+	}
+
+	assert.EqualError(t, cfg.Validate(), "node \"10.8.0.11:6379\" is listed multiple times")
+}
+
+func TestNodeConfigHostAndPort(t *testing.T) {
+	assert := assert.New(t)
+
+	host, port := NodeConfig{Address: "node1"}.HostAndPort(6379)
+	assert.Equal("node1", host)
+	assert.Equal(int64(6379), port, "Should use the default port")
+
+	_, port = NodeConfig{Address: "node1", Port: 6380}.HostAndPort(6379)
+	assert.Equal(int64(6380), port, "Should use the port of the node")
+
+	_, port = NodeConfig{Address: "node1:6381"}.HostAndPort(6379)
+	assert.Equal(int64(6381), port, "Should use the port from the address")
+}
+
+func TestValkeyConfigNodeIndex(t *testing.T) {
+	cfg := ValkeyConfig{
+		Port:  6379,
+		Nodes: []NodeConfig{{Address: "10.8.0.11"}, {Name: "node2", Address: "10.8.0.12:6380"}},
+	}
+
+	tMatrix := map[string]int{
+		"10.8.0.11":      0,
+		"10.8.0.11:6379": 0,
+		"node2":          1,
+		"10.8.0.12:6380": 1,
+		"10.8.0.12":      -1,
+		"unknown":        -1,
+	}
+
+	for name, expected := range tMatrix {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, expected, cfg.NodeIndex(name))
+		})
+	}
+}
+
+func TestNodeConfigDisplayName(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("node1", NodeConfig{Name: "node1", Address: "10.8.0.11"}.DisplayName(6379), "Should use the name")
+	assert.Equal("10.8.0.11:6379", NodeConfig{Address: "10.8.0.11"}.DisplayName(6379), "Should fall back to the address")
+}
+
+func TestNodeConfigConnection(t *testing.T) {
+	assert := assert.New(t)
+
+	global := ValkeyConfig{
+		Username: "globaluser",
+		Password: "globalpass",
+		TLS:      TLSConfig{Enabled: true},
+	}
+
+	assert.Equal(global.connection(), NodeConfig{Address: "node1"}.connection(global), "Should use global settings by default")
+
+	res := NodeConfig{
+		Address:      "node1",
+		PasswordFile: "/password",
+		TLS:          &TLSConfig{Enabled: true, ServerName: "node1.example.com"},
+	}.connection(global)
+	assert.Equal(connectionConfig{
+		Username:     "globaluser",
+		PasswordFile: "/password",
+		TLS:          TLSConfig{Enabled: true, ServerName: "node1.example.com"},
+	}, res, "Should override the global settings")
+}
@@ -0,0 +1,85 @@
+package failoverclient
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/valkey-io/valkey-go"
+)
+
+// The settings used for connecting to a valkey instance
+type connectionConfig struct {
+	Username     string
+	UsernameFile string
+	Password     string
+	PasswordFile string
+	TLS          TLSConfig
+}
+
+// Return the credentials used for the connection
+func (c connectionConfig) credentials() Credentials {
+	return Credentials{
+		Username:     c.Username,
+		UsernameFile: c.UsernameFile,
+		Password:     c.Password,
+		PasswordFile: c.PasswordFile,
+	}
+}
+
+// Create the client options used for connecting to valkey.
+// Credentials from files are read on every new connection, so rotated secrets are used without restarting.
+// The same applies to changed tls certificates.
+func newClientOption(cfg connectionConfig) (valkey.ClientOption, error) {
+	option := valkey.ClientOption{
+		Username:     cfg.Username,
+		Password:     cfg.Password,
+		DisableCache: true,
+		DisableRetry: true,
+	}
+	if cfg.UsernameFile != "" || cfg.PasswordFile != "" {
+		option.AuthCredentialsFn = func(valkey.AuthCredentialsContext) (valkey.AuthCredentials, error) {
+			return loadCredentials(cfg.Username, cfg.UsernameFile, cfg.Password, cfg.PasswordFile)
+		}
+	}
+	if cfg.TLS.Enabled {
+		loader, err := newTLSLoader(cfg.TLS)
+		if err != nil {
+			return valkey.ClientOption{}, err
+		}
+		option.TLSConfig = loader.current
+		option.DialCtxFn = loader.dial
+	}
+	return option, nil
+}
+
+// Return the credentials, reading them from the given files when set
+func loadCredentials(username, usernameFile, password, passwordFile string) (valkey.AuthCredentials, error) {
+	var err error
+	if usernameFile != "" {
+		username, err = readSecretFile(usernameFile)
+		if err != nil {
+			return valkey.AuthCredentials{}, fmt.Errorf("failed to read username file: %w", err)
+		}
+	}
+	if passwordFile != "" {
+		password, err = readSecretFile(passwordFile)
+		if err != nil {
+			return valkey.AuthCredentials{}, fmt.Errorf("failed to read password file: %w", err)
+		}
+	}
+	return valkey.AuthCredentials{
+		Username: username,
+		Password: password,
+	}, nil
+}
+
+// Read a secret from file, stripping the trailing newline
+func readSecretFile(path string) (string, error) {
+	// #nosec G304: Local users can decide on the secret file path freely.
+	f, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(f), "\r\n"), nil
+}
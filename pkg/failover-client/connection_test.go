@@ -0,0 +1,88 @@
+package failoverclient
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valkey-io/valkey-go"
+)
+
+func TestNewClientOption(t *testing.T) {
+	t.Run("Inline", func(t *testing.T) {
+		assert := assert.New(t)
+
+		option, err := newClientOption(connectionConfig{Username: "user", Password: "pass", TLS: TLSConfig{Enabled: true}})
+		require.NoError(t, err, "Should create client option")
+
+		assert.Equal("user", option.Username, "Should set username")
+		assert.Equal("pass", option.Password, "Should set password")
+		assert.Nil(option.AuthCredentialsFn, "Should not read credentials from file")
+		assert.NotNil(option.TLSConfig, "Should set tls config")
+		assert.NotNil(option.DialCtxFn, "Should dial with the current tls config")
+		assert.True(option.DisableCache, "Should disable cache")
+		assert.True(option.DisableRetry, "Should disable retry")
+	})
+	t.Run("FromFile", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		dir := t.TempDir()
+		usernameFile := filepath.Join(dir, "username")
+		passwordFile := filepath.Join(dir, "password")
+		require.NoError(os.WriteFile(usernameFile, []byte("user\n"), 0600))
+		require.NoError(os.WriteFile(passwordFile, []byte("pass\n"), 0600))
+
+		option, err := newClientOption(connectionConfig{UsernameFile: usernameFile, PasswordFile: passwordFile})
+		require.NoError(err, "Should create client option")
+		require.NotNil(option.AuthCredentialsFn, "Should read credentials from file")
+		assert.Nil(option.TLSConfig, "Should not set tls config")
+
+		creds, err := option.AuthCredentialsFn(valkey.AuthCredentialsContext{})
+		require.NoError(err, "Should read credentials")
+		assert.Equal(valkey.AuthCredentials{Username: "user", Password: "pass"}, creds, "Should strip trailing newlines")
+
+		require.NoError(os.WriteFile(passwordFile, []byte("rotated"), 0600))
+		creds, err = option.AuthCredentialsFn(valkey.AuthCredentialsContext{})
+		require.NoError(err, "Should read credentials")
+		assert.Equal("rotated", creds.Password, "Should read the rotated password")
+
+		require.NoError(os.Remove(usernameFile))
+		_, err = option.AuthCredentialsFn(valkey.AuthCredentialsContext{})
+		assert.Error(err, "Should fail when file is missing")
+	})
+}
+
+func TestNodeConnectPasswordFile(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	mr := miniredis.RunT(t)
+	mr.RequireAuth("first")
+
+	passwordFile := filepath.Join(t.TempDir(), "password")
+	require.NoError(os.WriteFile(passwordFile, []byte("first\n"), 0600))
+
+	option, err := newClientOption(connectionConfig{PasswordFile: passwordFile})
+	require.NoError(err, "Should create client option")
+	n := &node{
+		address: mr.Host(),
+		port:    int64(mr.Server().Addr().Port),
+		option:  option,
+	}
+
+	// Miniredis does not support INFO server, so the error shows if authentication succeeded
+	err = n.connect(t.Context())
+	assert.EqualError(err, "section (server) is not supported", "Should authenticate with password from file")
+
+	mr.RequireAuth("second")
+	err = n.connect(t.Context())
+	assert.ErrorContains(err, "WRONGPASS", "Should fail with the old password")
+
+	require.NoError(os.WriteFile(passwordFile, []byte("second\n"), 0600))
+	err = n.connect(t.Context())
+	assert.EqualError(err, "section (server) is not supported", "Should use the rotated password on reconnect")
+}
@@ -0,0 +1,126 @@
+package failoverclient
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+)
+
+type DampeningConfig struct {
+	// Number of consecutive checks a new master needs to be seen behind the virtual address
+	Observations int `yaml:"observations,omitempty"`
+	// How long a new master needs to be seen behind the virtual address
+	Duration time.Duration `yaml:"duration,omitempty"`
+	// Maximum number of switchovers within the window, 0 disables the limit
+	MaxSwitchovers int           `yaml:"maxSwitchovers,omitempty"`
+	Window         time.Duration `yaml:"window,omitempty"`
+}
+
+// Ensure that the given config is valid
+func (c DampeningConfig) Validate() error {
+	if c.Observations < 0 {
+		return fmt.Errorf("invalid dampening observations, can't be negative")
+	}
+	if c.Duration < 0 {
+		return fmt.Errorf("invalid dampening duration, can't be negative")
+	}
+	if c.MaxSwitchovers < 0 {
+		return fmt.Errorf("invalid dampening max switchovers, can't be negative")
+	}
+	if c.MaxSwitchovers > 0 && c.Window <= 0 {
+		return fmt.Errorf("invalid dampening window, needs to be greater than 0")
+	}
+	return nil
+}
+
+// A new master seen behind the virtual address, that is not yet stable
+type PendingSwitchover struct {
+	Node  string    `json:"node"`
+	RunID string    `json:"runID"`
+	Since time.Time `json:"since"`
+	// Number of consecutive checks the node was seen behind the virtual address
+	Observations int `json:"observations"`
+}
+
+// Check if the new master was seen behind the virtual address long enough
+// and if the switch is allowed by the switchover limit.
+// The first master is always accepted immediately.
+func (c *FailoverClient) dampenSwitchover(newMaster *node) bool {
+	if c.masterNode == nil {
+		return true
+	}
+
+	pending := c.pendingSwitchover
+	if pending == nil || pending.RunID != newMaster.runID {
+		pending = &PendingSwitchover{
+			Node:  newMaster.name,
+			RunID: newMaster.runID,
+			Since: time.Now(),
+		}
+		c.pendingSwitchover = pending
+	}
+	pending.Observations++
+	c.updateDampeningMetrics()
+
+	cfg := c.cfg.Dampening
+	if pending.Observations < cfg.Observations || time.Since(pending.Since) < cfg.Duration {
+		if pending.Observations == 1 {
+			slog.Info("New master behind the virtual address, waiting for it to be stable", slog.String("node", pending.Node), slog.Int("observations", cfg.Observations), slog.Duration("duration", cfg.Duration))
+		}
+		return false
+	}
+
+	return c.allowSwitchover()
+}
+
+// Check if another switchover is allowed within the current window
+func (c *FailoverClient) allowSwitchover() bool {
+	cfg := c.cfg.Dampening
+	if cfg.MaxSwitchovers == 0 {
+		c.setSwitchoverLimited(false)
+		return true
+	}
+
+	cutoff := time.Now().Add(-cfg.Window)
+	c.switchovers = slices.DeleteFunc(c.switchovers, func(t time.Time) bool {
+		return t.Before(cutoff)
+	})
+
+	limited := len(c.switchovers) >= cfg.MaxSwitchovers
+	c.setSwitchoverLimited(limited)
+	return !limited
+}
+
+// Remember the switchover for the switchover limit and forget the pending one
+func (c *FailoverClient) countSwitchover(oldMaster *node) {
+	c.pendingSwitchover = nil
+	if oldMaster != nil && c.cfg.Dampening.MaxSwitchovers > 0 {
+		c.switchovers = append(c.switchovers, time.Now())
+	}
+	c.updateDampeningMetrics()
+}
+
+// Forget the pending switchover, when the virtual address points to the current master again
+func (c *FailoverClient) cancelSwitchover() {
+	if c.pendingSwitchover == nil && !c.switchoverLimited {
+		return
+	}
+	if c.pendingSwitchover != nil {
+		slog.Info("Virtual address is pointing to the current master again, cancelling switchover", slog.String("node", c.pendingSwitchover.Node), slog.Int("observations", c.pendingSwitchover.Observations))
+	}
+	c.pendingSwitchover = nil
+	c.setSwitchoverLimited(false)
+	c.updateDampeningMetrics()
+}
+
+// Save if switchovers are currently refused due to the switchover limit, logging when it changes
+func (c *FailoverClient) setSwitchoverLimited(limited bool) {
+	if limited && !c.switchoverLimited {
+		slog.Error("Too many switchovers, refusing to switch to the new master", slog.Int("maxSwitchovers", c.cfg.Dampening.MaxSwitchovers), slog.Duration("window", c.cfg.Dampening.Window))
+	} else if !limited && c.switchoverLimited {
+		slog.Info("Switchovers are allowed again")
+	}
+	c.switchoverLimited = limited
+	c.updateDampeningMetrics()
+}
@@ -0,0 +1,152 @@
+package failoverclient
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDampeningConfigValidate(t *testing.T) {
+	tMatrix := map[string]struct {
+		cfg DampeningConfig
+		err bool
+	}{
+		"Empty": {
+			cfg: DampeningConfig{},
+		},
+		"Valid": {
+			cfg: DampeningConfig{Observations: 3, Duration: time.Second, MaxSwitchovers: 2, Window: time.Hour},
+		},
+		"NegativeObservations": {
+			cfg: DampeningConfig{Observations: -1},
+			err: true,
+		},
+		"NegativeDuration": {
+			cfg: DampeningConfig{Duration: -time.Second},
+			err: true,
+		},
+		"NegativeMaxSwitchovers": {
+			cfg: DampeningConfig{MaxSwitchovers: -1},
+			err: true,
+		},
+		"MissingWindow": {
+			cfg: DampeningConfig{MaxSwitchovers: 1},
+			err: true,
+		},
+	}
+
+	for name, tCase := range tMatrix {
+		t.Run(name, func(t *testing.T) {
+			err := tCase.cfg.Validate()
+			if tCase.err {
+				assert.Error(t, err, "Should be invalid")
+			} else {
+				assert.NoError(t, err, "Should be valid")
+			}
+		})
+	}
+}
+
+func TestDampenSwitchover(t *testing.T) {
+	t.Run("FirstMaster", func(t *testing.T) {
+		c := &FailoverClient{cfg: ValkeyConfig{Dampening: DampeningConfig{Observations: 3}}}
+
+		assert.True(t, c.dampenSwitchover(&node{name: "node1", runID: "runid1"}), "Should accept the first master immediately")
+		assert.Nil(t, c.pendingSwitchover, "Should not have a pending switchover")
+	})
+	t.Run("Disabled", func(t *testing.T) {
+		c := &FailoverClient{masterNode: &node{name: "node1"}}
+
+		assert.True(t, c.dampenSwitchover(&node{name: "node2", runID: "runid2"}), "Should switch immediately")
+	})
+	t.Run("Observations", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		c := &FailoverClient{
+			masterNode: &node{name: "node1"},
+			cfg:        ValkeyConfig{Dampening: DampeningConfig{Observations: 3}},
+		}
+		node2 := &node{name: "node2", runID: "runid2"}
+
+		assert.False(c.dampenSwitchover(node2), "Should wait on the first observation")
+		require.NotNil(c.pendingSwitchover, "Should remember the pending switchover")
+		assert.Equal("node2", c.pendingSwitchover.Node, "Should contain the new master")
+		assert.Equal(1.0, testutil.ToFloat64(switchoverPendingGauge), "Should expose the pending switchover")
+		since := c.pendingSwitchover.Since
+
+		assert.False(c.dampenSwitchover(node2), "Should wait on the second observation")
+		assert.True(c.dampenSwitchover(node2), "Should switch on the third observation")
+		assert.Equal(3, c.pendingSwitchover.Observations, "Should count the observations")
+		assert.Equal(since, c.pendingSwitchover.Since, "Should keep the time of the first observation")
+
+		assert.False(c.dampenSwitchover(&node{name: "node3", runID: "runid3"}), "Should start over for a different node")
+		assert.Equal(1, c.pendingSwitchover.Observations, "Should reset the observations")
+
+		c.cancelSwitchover()
+		assert.Nil(c.pendingSwitchover, "Should cancel the pending switchover")
+		assert.Equal(0.0, testutil.ToFloat64(switchoverPendingGauge), "Should clear the metric")
+	})
+	t.Run("Duration", func(t *testing.T) {
+		assert := assert.New(t)
+
+		c := &FailoverClient{
+			masterNode: &node{name: "node1"},
+			cfg:        ValkeyConfig{Dampening: DampeningConfig{Duration: time.Hour}},
+		}
+		node2 := &node{name: "node2", runID: "runid2"}
+
+		assert.False(c.dampenSwitchover(node2), "Should wait for the duration")
+		c.pendingSwitchover.Since = time.Now().Add(-2 * time.Hour)
+		assert.True(c.dampenSwitchover(node2), "Should switch once the duration passed")
+	})
+	t.Run("Limit", func(t *testing.T) {
+		assert := assert.New(t)
+
+		c := &FailoverClient{
+			masterNode: &node{name: "node1"},
+			cfg:        ValkeyConfig{Dampening: DampeningConfig{MaxSwitchovers: 2, Window: time.Hour}},
+		}
+		node2 := &node{name: "node2", runID: "runid2"}
+
+		c.switchovers = []time.Time{time.Now().Add(-2 * time.Hour), time.Now()}
+		assert.True(c.dampenSwitchover(node2), "Should allow the switchover when the old ones are outside the window")
+		assert.Len(c.switchovers, 1, "Should forget switchovers outside the window")
+
+		c.countSwitchover(c.masterNode)
+		assert.Len(c.switchovers, 2, "Should count the switchover")
+		assert.Nil(c.pendingSwitchover, "Should clear the pending switchover")
+
+		assert.False(c.dampenSwitchover(node2), "Should refuse the switchover over the limit")
+		assert.True(c.switchoverLimited, "Should remember that the limit is reached")
+		assert.Equal(1.0, testutil.ToFloat64(switchoverLimitedGauge), "Should raise the alert")
+
+		c.cancelSwitchover()
+		assert.False(c.switchoverLimited, "Should clear the limit when the virtual address points to the current master again")
+		assert.Equal(0.0, testutil.ToFloat64(switchoverLimitedGauge), "Should clear the alert")
+	})
+}
+
+func TestReconcileDampening(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	c, fakes := newFakeCluster(t, ValkeyConfig{Dampening: DampeningConfig{Observations: 2}}, "runid1", "runid2")
+	require.True(c.reconcile(), "Should accept the first master immediately")
+	commands := fakes[1].receivedCommands()
+
+	c.virtualAddress = c.nodes[1].address
+	c.port = c.nodes[1].port
+	assert.False(c.reconcile(), "Should not be ready while the switchover is pending")
+	assert.Same(c.nodes[0], c.masterNode, "Should keep the current master")
+	assert.Equal(commands, fakes[1].receivedCommands(), "Should not change the nodes")
+
+	c.recordIteration(false)
+	require.NotNil(c.Status().PendingSwitchover, "Should show the pending switchover in the status")
+
+	assert.True(c.reconcile(), "Should switch once the new master is stable")
+	assert.Same(c.nodes[1], c.masterNode, "Should switch to the new master")
+}
@@ -0,0 +1,50 @@
+package failoverclient
+
+import (
+	"log/slog"
+)
+
+// Mark the node as drained or not, forgetting the role it had before.
+// Drained nodes are neither changed nor accepted as master.
+func (n *node) setDrained(drained bool) {
+	if n.drained == drained {
+		return
+	}
+	if drained {
+		slog.Info("Draining node, it will not be changed anymore", slog.String("node", n.name))
+	} else {
+		slog.Info("Node is no longer drained", slog.String("node", n.name))
+	}
+	n.drained = drained
+	// The node might have been changed during the maintenance
+	n.roleCache = &roleCache{ttl: n.roleCache.ttl}
+	n.resetLink()
+	n.setPending()
+}
+
+// Save the drained node the virtual address is pointing to, logging when it changes
+func (c *FailoverClient) setDrainedMaster(n *node) {
+	switch {
+	case n == nil && c.drainedMaster != nil:
+		slog.Info("Virtual address is no longer pointing to a drained node", slog.String("node", c.drainedMaster.name))
+	case n != nil && n != c.drainedMaster:
+		slog.Error("Virtual address is pointing to a drained node, refusing to accept it as master", slog.String("node", n.name), slog.String(runID, n.runID))
+	}
+	c.drainedMaster = n
+
+	value := 0.0
+	if n != nil {
+		value = 1
+	}
+	drainedMasterGauge.Set(value)
+}
+
+// Return the node with the given run_id, nil if there is none
+func (c *FailoverClient) nodeByRunID(id string) *node {
+	for _, n := range c.nodes {
+		if n.runID == id {
+			return n
+		}
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package failoverclient
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNodeSetDrained(t *testing.T) {
+	assert := assert.New(t)
+
+	n := &node{
+		name:          "node1",
+		roleCache:     &roleCache{ttl: time.Hour},
+		linkStatus:    linkStatusDown,
+		linkDownSince: time.Now(),
+	}
+	n.roleCache.Save(master, nil)
+
+	n.setDrained(true)
+	assert.True(n.drained, "Should drain the node")
+	assert.False(n.roleCache.IsMaster(), "Should forget the cached role")
+	assert.Equal(time.Hour, n.roleCache.ttl, "Should keep the role cache ttl")
+	assert.Empty(n.linkStatus, "Should reset the link status")
+	assert.Zero(n.linkDownSince, "Should reset the link down time")
+
+	n.roleCache.Save(master, nil)
+	n.setDrained(true)
+	assert.True(n.roleCache.IsMaster(), "Should not reset the node when nothing changed")
+
+	n.setDrained(false)
+	assert.False(n.drained, "Should undrain the node")
+	assert.False(n.roleCache.IsMaster(), "Should forget the role from before the maintenance")
+}
+
+func TestApplyConfigDrained(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	c := newTestClient(t, newTestConfig("node1", "node2"))
+	node1 := c.nodes[0]
+	assert.False(node1.drained, "Should not drain nodes by default")
+
+	cfg := newTestConfig("node1", "node2")
+	cfg.Nodes[0].Drained = true
+	require.NoError(c.applyConfig(cfg), "Should apply config")
+
+	assert.Same(node1, c.nodes[0], "Should keep the node")
+	assert.True(node1.drained, "Should drain the node on reload")
+	assert.False(c.nodes[1].drained, "Should not drain the other node")
+}
+
+func TestReconcileDrainedNode(t *testing.T) {
+	assert := assert.New(t)
+
+	c, fakes := newFakeCluster(t, ValkeyConfig{}, "runid1", "runid2", "runid3")
+	c.nodes[2].drained = true
+
+	assert.True(c.reconcile(), "Should be ready")
+	assert.NotEmpty(fakes[1].receivedCommands(), "Should change the other nodes")
+	assert.Empty(fakes[2].receivedCommands(), "Should not change the drained node")
+
+	c.detectSplitBrain()
+	assert.False(c.SplitBrain().Active, "Should ignore the drained node for the split-brain detection")
+}
+
+func TestReconcileDrainedMaster(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	c, fakes := newFakeCluster(t, ValkeyConfig{}, "runid1", "runid2", "runid3")
+	fakes[0].setDataset(10, 100)
+	require.True(c.reconcile(), "Should resolve the initial master")
+
+	// The virtual address moves to the drained node
+	c.nodes[1].drained = true
+	c.virtualAddress = c.nodes[1].address
+	c.port = c.nodes[1].port
+	errors := testutil.ToFloat64(virtualAddressErrorsTotal.WithLabelValues(vaErrorDrainedMaster))
+
+	assert.False(c.reconcile(), "Should not be ready while the virtual address points to a drained node")
+	assert.Same(c.nodes[0], c.masterNode, "Should keep the current master")
+	assert.Equal(1.0, testutil.ToFloat64(drainedMasterGauge), "Should raise the alert")
+	assert.Equal(errors+1, testutil.ToFloat64(virtualAddressErrorsTotal.WithLabelValues(vaErrorDrainedMaster)), "Should count the error")
+
+	c.recordIteration(false)
+	assert.Equal(c.nodes[1].name, c.Status().DrainedMaster, "Should show the drained master in the status")
+
+	c.nodes[1].setDrained(false)
+	fakes[1].setDataset(10, 100)
+	assert.True(c.reconcile(), "Should accept the node once it is no longer drained")
+	assert.Same(c.nodes[1], c.masterNode, "Should switch to the new master")
+	assert.Nil(c.drainedMaster, "Should clear the drained master")
+	assert.Equal(0.0, testutil.ToFloat64(drainedMasterGauge), "Should clear the alert")
+}
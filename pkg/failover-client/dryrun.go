@@ -0,0 +1,46 @@
+package failoverclient
+
+import (
+	"log/slog"
+	"slices"
+	"time"
+)
+
+// How often the pending differences are summarized in dry-run mode
+const dryRunSummaryInterval = time.Minute
+
+// Remember the commands that would be send to the node, if it wasn't for the dry-run mode.
+// Logs the commands when they change.
+func (n *node) setPending(cmds ...string) {
+	if len(cmds) > 0 && !slices.Equal(n.pending, cmds) {
+		slog.Info("Dry-run, not sending commands to node", slog.String("node", n.name), slog.Any("commands", cmds))
+	}
+	n.pending = cmds
+	dryRunPendingGauge.WithLabelValues(n.String()).Set(float64(len(cmds)))
+}
+
+// Log the differences between the desired and the actual topology.
+// Only logs once per summary interval and only in dry-run mode.
+func (c *FailoverClient) summarizeDryRun() {
+	if !c.cfg.DryRun || time.Since(c.lastDryRunSummary) < dryRunSummaryInterval {
+		return
+	}
+	c.lastDryRunSummary = time.Now()
+
+	master := ""
+	if c.masterNode != nil {
+		master = c.masterNode.name
+	}
+
+	pending := make(map[string][]string)
+	for _, n := range c.nodes {
+		if len(n.pending) > 0 {
+			pending[n.name] = n.pending
+		}
+	}
+	if len(pending) == 0 {
+		slog.Info("Dry-run summary, the nodes match the desired topology", slog.String("master", master))
+		return
+	}
+	slog.Warn("Dry-run summary, the nodes differ from the desired topology", slog.String("master", master), slog.Int("nodes", len(pending)), slog.Any("pending", pending))
+}
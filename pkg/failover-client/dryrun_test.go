@@ -0,0 +1,102 @@
+package failoverclient
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNodeDryRun(t *testing.T) {
+	t.Run("Master", func(t *testing.T) {
+		assert := assert.New(t)
+
+		f := newFakeValkey(t, "runid1")
+		f.setSlaveOf(&node{address: "other", port: 6379}, linkStatusUp)
+		n := f.newNode(t)
+		n.dryRun = true
+
+		assert.NoError(n.master(t.Context()), "Should not fail")
+		assert.Empty(f.receivedCommands(), "Should not send any commands")
+		assert.Equal([]string{"REPLICAOF NO ONE"}, n.pending, "Should remember the pending command")
+		assert.False(n.roleCache.IsMaster(), "Should not cache the role")
+		assert.Equal(1.0, testutil.ToFloat64(dryRunPendingGauge.WithLabelValues(n.String())), "Should expose the pending command")
+
+		assert.NoError(f.newNode(t).master(t.Context()), "Should promote the node without dry-run")
+		assert.NoError(n.master(t.Context()), "Should not fail")
+		assert.Empty(n.pending, "Should clear the pending command once the node is master")
+		assert.Equal(0.0, testutil.ToFloat64(dryRunPendingGauge.WithLabelValues(n.String())), "Should clear the metric")
+	})
+	t.Run("Slave", func(t *testing.T) {
+		assert := assert.New(t)
+
+		f := newFakeValkey(t, "runid1")
+		n := f.newNode(t)
+		n.dryRun = true
+		n.fencing = FencingConfig{Enabled: true, PauseTimeout: DEFAULT_FENCING_PAUSE_TIMEOUT}
+		newMaster := &node{address: "master", port: 6379, replication: Credentials{Password: "secret"}}
+
+		assert.NoError(n.slave(t.Context(), newMaster), "Should not fail")
+		assert.Empty(f.receivedCommands(), "Should neither fence, configure credentials nor send REPLICAOF")
+		assert.Equal([]string{"REPLICAOF master 6379"}, n.pending, "Should remember the pending command")
+		assert.False(n.roleCache.IsSlaveOf(newMaster), "Should not cache the role")
+	})
+	t.Run("LinkRepair", func(t *testing.T) {
+		assert := assert.New(t)
+
+		f := newFakeValkey(t, "runid1")
+		newMaster := &node{address: "master", port: 6379}
+		f.setSlaveOf(newMaster, linkStatusDown)
+		n := f.newNode(t)
+		n.dryRun = true
+		n.linkDownTimeout = time.Millisecond
+		n.linkDownSince = time.Now().Add(-time.Second)
+
+		assert.NoError(n.slave(t.Context(), newMaster), "Should not fail")
+		assert.Empty(f.receivedCommands(), "Should not repair the link")
+		assert.Equal([]string{"CLIENT KILL TYPE MASTER"}, n.pending, "Should remember the repair")
+
+		f.setSlaveOf(newMaster, linkStatusUp)
+		assert.NoError(n.slave(t.Context(), newMaster), "Should not fail")
+		assert.Empty(n.pending, "Should clear the pending commands once the link is up")
+	})
+}
+
+func TestReconcileDryRun(t *testing.T) {
+	assert := assert.New(t)
+
+	c, fakes := newFakeCluster(t, ValkeyConfig{DryRun: true}, "runid1", "runid2")
+
+	assert.True(c.reconcile(), "Should resolve the master")
+	assert.Same(c.nodes[0], c.masterNode, "Should track the master behind the virtual address")
+	for i, f := range fakes {
+		assert.Emptyf(f.receivedCommands(), "Should not change node %d", i)
+	}
+
+	c.recordIteration(true)
+	status := c.Status()
+	assert.True(status.DryRun, "Should show the dry-run mode in the status")
+	assert.Empty(status.Nodes[0].PendingCommands, "Should have nothing pending for the master")
+	assert.Equal([]string{"REPLICAOF " + c.nodes[0].address + " " + strconv.FormatInt(c.nodes[0].port, 10)}, status.Nodes[1].PendingCommands, "Should show the pending commands of the slave")
+}
+
+func TestSummarizeDryRun(t *testing.T) {
+	assert := assert.New(t)
+
+	c := &FailoverClient{
+		nodes: []*node{{name: "node1", pending: []string{"REPLICAOF NO ONE"}}},
+	}
+
+	c.summarizeDryRun()
+	assert.Zero(c.lastDryRunSummary, "Should not summarize without dry-run")
+
+	c.cfg.DryRun = true
+	c.summarizeDryRun()
+	last := c.lastDryRunSummary
+	assert.NotZero(last, "Should summarize in dry-run mode")
+
+	c.summarizeDryRun()
+	assert.Equal(last, c.lastDryRunSummary, "Should only summarize once per interval")
+}
@@ -0,0 +1,52 @@
+package failoverclient
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+type FencingConfig struct {
+	Enabled      bool          `yaml:"enabled,omitempty"`
+	PauseTimeout time.Duration `yaml:"pauseTimeout,omitempty"`
+	KillClients  bool          `yaml:"killClients,omitempty"`
+}
+
+// Ensure that the given config is valid
+func (c FencingConfig) Validate() error {
+	if c.Enabled && c.PauseTimeout <= 0 {
+		return fmt.Errorf("invalid fencing pause timeout, needs to be greater than 0")
+	}
+	return nil
+}
+
+// Stop a former master from accepting writes before it is demoted.
+// The pause expires on its own, in case the node can't be demoted.
+func (n *node) fence(ctx context.Context) error {
+	slog.Warn("Fencing former master before demoting it", slog.String("node", n.name), slog.Bool("killClients", n.fencing.KillClients))
+	fencingsTotal.WithLabelValues(n.String()).Inc()
+
+	err := n.client.Do(ctx, n.client.B().ClientPause().Timeout(n.fencing.PauseTimeout.Milliseconds()).Write().Build()).Error()
+	if err != nil {
+		return fmt.Errorf("failed to pause writes: %w", err)
+	}
+
+	if n.fencing.KillClients {
+		// Our own connection is skipped, so the demotion can continue
+		err = n.client.Do(ctx, n.client.B().ClientKill().TypeNormal().SkipmeYes().Build()).Error()
+		if err != nil {
+			return fmt.Errorf("failed to kill client connections: %w", err)
+		}
+	}
+	return nil
+}
+
+// Resume writes after the node was demoted, as a slave it rejects them anyway.
+// Failing is not critical, since the pause expires on its own.
+func (n *node) unfence(ctx context.Context) {
+	err := n.client.Do(ctx, n.client.B().ClientUnpause().Build()).Error()
+	if err != nil {
+		slog.Warn("Failed to resume writes after demoting former master, waiting for the pause to expire", slog.String("node", n.name), "err", err)
+	}
+}
@@ -0,0 +1,75 @@
+package failoverclient
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFencingConfigValidate(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.NoError(FencingConfig{}.Validate(), "Disabled fencing should be valid")
+	assert.NoError(FencingConfig{Enabled: true, PauseTimeout: time.Second}.Validate(), "Should be valid")
+	assert.Error(FencingConfig{Enabled: true}.Validate(), "Should require a pause timeout")
+}
+
+func TestNodeSlaveFencing(t *testing.T) {
+	newMaster := &node{name: "master", address: "master", port: 6379}
+
+	t.Run("Disabled", func(t *testing.T) {
+		assert := assert.New(t)
+
+		f := newFakeValkey(t, "oldmaster")
+		n := f.newNode(t)
+
+		assert.NoError(n.slave(t.Context(), newMaster), "Should demote node")
+		assert.Equal([]string{"REPLICAOF master 6379"}, f.receivedCommands(), "Should not fence the node")
+	})
+	t.Run("PauseWrites", func(t *testing.T) {
+		assert := assert.New(t)
+
+		f := newFakeValkey(t, "oldmaster")
+		n := f.newNode(t)
+		n.fencing = FencingConfig{Enabled: true, PauseTimeout: 5 * time.Second}
+		fencings := testutil.ToFloat64(fencingsTotal.WithLabelValues(n.String()))
+
+		assert.NoError(n.slave(t.Context(), newMaster), "Should demote node")
+		assert.Equal([]string{"CLIENT PAUSE 5000 WRITE", "REPLICAOF master 6379", "CLIENT UNPAUSE"}, f.receivedCommands(), "Should pause writes until the node is demoted")
+		assert.Equal(fencings+1, testutil.ToFloat64(fencingsTotal.WithLabelValues(n.String())), "Should count the fencing")
+	})
+	t.Run("KillClients", func(t *testing.T) {
+		assert := assert.New(t)
+
+		f := newFakeValkey(t, "oldmaster")
+		n := f.newNode(t)
+		n.fencing = FencingConfig{Enabled: true, PauseTimeout: time.Second, KillClients: true}
+
+		assert.NoError(n.slave(t.Context(), newMaster), "Should demote node")
+		assert.Equal([]string{"CLIENT PAUSE 1000 WRITE", "CLIENT KILL TYPE NORMAL SKIPME YES", "REPLICAOF master 6379", "CLIENT UNPAUSE"}, f.receivedCommands(), "Should kill the clients after pausing writes")
+	})
+	t.Run("OnlyMasters", func(t *testing.T) {
+		assert := assert.New(t)
+
+		f := newFakeValkey(t, "slave")
+		n := f.newNode(t)
+		n.fencing = FencingConfig{Enabled: true, PauseTimeout: time.Second, KillClients: true}
+		f.setSlaveOf(&node{address: "othermaster", port: 6379}, linkStatusUp)
+
+		assert.NoError(n.slave(t.Context(), newMaster), "Should change master")
+		assert.Equal([]string{"REPLICAOF master 6379"}, f.receivedCommands(), "Should not fence a slave")
+	})
+	t.Run("FailedDemotion", func(t *testing.T) {
+		assert := assert.New(t)
+
+		f := newFakeValkey(t, "oldmaster")
+		n := f.newNode(t)
+		n.fencing = FencingConfig{Enabled: true, PauseTimeout: time.Second}
+		m := &node{name: "master", address: "master", port: 6379, replication: Credentials{PasswordFile: "/not/existing"}}
+
+		assert.Error(n.slave(t.Context(), m), "Should fail to demote node")
+		assert.Equal([]string{"CLIENT PAUSE 1000 WRITE"}, f.receivedCommands(), "Should keep the pause until it expires")
+	})
+}
@@ -0,0 +1,120 @@
+package failoverclient
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	valkeyinfo "github.com/heathcliff26/valkey-keepalived/pkg/valkey-info"
+)
+
+// A promotion that was refused, since it would wipe the data of the other nodes
+type BlockedPromotion struct {
+	// The empty node behind the virtual address
+	Node  string    `json:"node"`
+	RunID string    `json:"runID"`
+	Since time.Time `json:"since"`
+	// The nodes still holding data
+	PopulatedNodes []string `json:"populatedNodes"`
+}
+
+// The size of the dataset of a node
+type dataset struct {
+	keys   int64
+	offset int64
+}
+
+// Fetch the size of the dataset from valkey
+func (n *node) getDataset(ctx context.Context) (dataset, error) {
+	res, err := n.client.Do(ctx, n.client.B().Info().Section(valkeyinfo.SectionKeyspace, valkeyinfo.SectionReplication).Build()).ToString()
+	if err != nil {
+		return dataset{}, err
+	}
+	info := valkeyinfo.Parse(res)
+	return dataset{
+		keys:   info.Keys(),
+		offset: info.Replication.Offset(),
+	}, nil
+}
+
+// Check if the given node can be promoted without wiping the data of the other nodes.
+// Refuses the promotion when the candidate is empty, while another node holds data and is further ahead in replication.
+// Needs to be called while no jobs are running.
+func (c *FailoverClient) checkPromotion(candidate *node) bool {
+	if c.cfg.AllowEmptyPromotion {
+		c.setBlockedPromotion(nil)
+		return true
+	}
+
+	datasets := make(map[*node]dataset, len(c.nodes))
+	var datasetsLock sync.Mutex
+	var candidateErr error
+	c.parallelJob(c.timeout, func(ctx context.Context, n *node) {
+		if n.client == nil {
+			return
+		}
+		ds, err := n.getDataset(ctx)
+		if err != nil {
+			if n == candidate {
+				candidateErr = err
+			}
+			slog.Debug("Failed to retrieve dataset size", slog.String("node", n.name), "err", err)
+			return
+		}
+		datasetsLock.Lock()
+		datasets[n] = ds
+		datasetsLock.Unlock()
+	})
+
+	target, ok := datasets[candidate]
+	if !ok {
+		if candidateErr == nil {
+			candidateErr = fmt.Errorf("node is not up")
+		}
+		candidate.setError(candidateErr)
+		slog.Error("Could not verify the dataset of the new master, refusing to promote it", slog.String("node", candidate.name), "err", candidateErr)
+		return false
+	}
+
+	var populated []string
+	if target.keys == 0 {
+		for _, n := range c.nodes {
+			ds, ok := datasets[n]
+			if n == candidate || !ok {
+				continue
+			}
+			if ds.keys > 0 && ds.offset > target.offset {
+				populated = append(populated, n.name)
+			}
+		}
+	}
+	if len(populated) == 0 {
+		c.setBlockedPromotion(nil)
+		return true
+	}
+
+	c.setBlockedPromotion(&BlockedPromotion{
+		Node:           candidate.name,
+		RunID:          candidate.runID,
+		PopulatedNodes: populated,
+	})
+	return false
+}
+
+// Save the currently blocked promotion, logging when it changes
+func (c *FailoverClient) setBlockedPromotion(blocked *BlockedPromotion) {
+	prev := c.blockedPromotion
+	switch {
+	case blocked == nil && prev != nil:
+		slog.Info("Promotion is not blocked anymore", slog.String("node", prev.Node))
+	case blocked != nil && (prev == nil || prev.RunID != blocked.RunID):
+		blocked.Since = time.Now()
+		slog.Error("Refusing to promote empty node over populated nodes, keeping all nodes as they are. Set allowEmptyPromotion to override.", slog.String("node", blocked.Node), slog.Any("populatedNodes", blocked.PopulatedNodes))
+	case blocked != nil:
+		blocked.Since = prev.Since
+	}
+	c.blockedPromotion = blocked
+	c.updatePromotionMetrics()
+}
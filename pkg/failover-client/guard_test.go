@@ -0,0 +1,106 @@
+package failoverclient
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckPromotion(t *testing.T) {
+	t.Run("EmptyCluster", func(t *testing.T) {
+		c, _ := newFakeCluster(t, ValkeyConfig{}, "runid1", "runid2", "runid3")
+
+		assert.True(t, c.checkPromotion(c.nodes[0]), "Should allow promotion when all nodes are empty")
+		assert.Nil(t, c.blockedPromotion, "Should not block the promotion")
+	})
+	t.Run("PopulatedCandidate", func(t *testing.T) {
+		c, fakes := newFakeCluster(t, ValkeyConfig{}, "runid1", "runid2", "runid3")
+		fakes[0].setDataset(10, 50)
+		fakes[1].setDataset(20, 100)
+
+		assert.True(t, c.checkPromotion(c.nodes[0]), "Should allow promotion of a node with data")
+	})
+	t.Run("EmptyCandidate", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		c, fakes := newFakeCluster(t, ValkeyConfig{}, "runid1", "runid2", "runid3")
+		fakes[1].setDataset(10, 100)
+		fakes[2].setDataset(10, 100)
+
+		assert.False(c.checkPromotion(c.nodes[0]), "Should refuse promotion of an empty node")
+		require.NotNil(c.blockedPromotion, "Should remember the blocked promotion")
+		assert.Equal(c.nodes[0].name, c.blockedPromotion.Node, "Should contain the candidate")
+		assert.Equal("runid1", c.blockedPromotion.RunID, "Should contain the run_id of the candidate")
+		assert.Equal([]string{c.nodes[1].name, c.nodes[2].name}, c.blockedPromotion.PopulatedNodes, "Should list the populated nodes")
+		assert.NotZero(c.blockedPromotion.Since, "Should remember since when the promotion is blocked")
+		assert.Equal(1.0, testutil.ToFloat64(promotionBlockedGauge), "Should expose the blocked promotion")
+
+		since := c.blockedPromotion.Since
+		assert.False(c.checkPromotion(c.nodes[0]), "Should still refuse promotion")
+		assert.Equal(since, c.blockedPromotion.Since, "Should keep the time of the first refusal")
+
+		fakes[0].setDataset(10, 100)
+		assert.True(c.checkPromotion(c.nodes[0]), "Should allow promotion once the node has data")
+		assert.Nil(c.blockedPromotion, "Should clear the blocked promotion")
+		assert.Equal(0.0, testutil.ToFloat64(promotionBlockedGauge), "Should clear the metric")
+	})
+	t.Run("CandidateAhead", func(t *testing.T) {
+		c, fakes := newFakeCluster(t, ValkeyConfig{}, "runid1", "runid2", "runid3")
+		fakes[0].setDataset(0, 200)
+		fakes[1].setDataset(10, 100)
+
+		assert.True(t, c.checkPromotion(c.nodes[0]), "Should allow promotion when the candidate is further ahead")
+	})
+	t.Run("Override", func(t *testing.T) {
+		c, fakes := newFakeCluster(t, ValkeyConfig{}, "runid1", "runid2", "runid3")
+		c.cfg.AllowEmptyPromotion = true
+		fakes[1].setDataset(10, 100)
+
+		assert.True(t, c.checkPromotion(c.nodes[0]), "Should allow promotion when overridden")
+		assert.Nil(t, c.blockedPromotion, "Should not block the promotion")
+	})
+	t.Run("CandidateDown", func(t *testing.T) {
+		c, _ := newFakeCluster(t, ValkeyConfig{}, "runid1", "runid2", "runid3")
+		c.nodes[0].close()
+
+		assert.False(t, c.checkPromotion(c.nodes[0]), "Should refuse promotion when the dataset can't be verified")
+		assert.Error(t, c.nodes[0].lastError, "Should set the error on the node")
+	})
+}
+
+func TestReconcileBlockedPromotion(t *testing.T) {
+	assert := assert.New(t)
+
+	c, fakes := newFakeCluster(t, ValkeyConfig{}, "runid1", "runid2", "runid3")
+	fakes[1].setDataset(10, 100)
+	fakes[2].setSlaveOf(c.nodes[1], linkStatusUp)
+	fakes[2].setDataset(10, 100)
+
+	// The empty node 0 is behind the virtual address
+	c.virtualAddress = c.nodes[0].address
+	c.port = c.nodes[0].port
+	c.clientOption = c.nodes[0].option
+	c.masterNode = c.nodes[1]
+	c.currentMaster = "runid2"
+
+	assert.False(c.reconcile(), "Should not be ready while the promotion is blocked")
+	assert.Same(c.nodes[1], c.masterNode, "Should keep the current master")
+	for i, f := range fakes {
+		assert.Emptyf(f.receivedCommands(), "Should not reconfigure node %d", i)
+	}
+
+	c.recordIteration(false)
+	if assert.NotNil(c.Status().PromotionBlocked, "Should expose the blocked promotion in the status") {
+		assert.Equal(c.nodes[0].name, c.Status().PromotionBlocked.Node, "Should contain the blocked node")
+	}
+
+	c.cfg.AllowEmptyPromotion = true
+	assert.True(c.reconcile(), "Should promote the node when overridden")
+	assert.Same(c.nodes[0], c.masterNode, "Should switch to the new master")
+	assert.Nil(c.blockedPromotion, "Should clear the blocked promotion")
+	assert.Contains(fakes[1].receivedCommands(), "REPLICAOF "+c.nodes[0].address+" "+strconv.FormatInt(c.nodes[0].port, 10), "Should make the other nodes slaves")
+}
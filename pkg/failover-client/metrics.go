@@ -0,0 +1,227 @@
+package failoverclient
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const metricsNamespace = "valkey_keepalived"
+
+const (
+	vaErrorConnect       = "connect"
+	vaErrorInfo          = "info"
+	vaErrorUnknownMaster = "unknown_master"
+	vaErrorDrainedMaster = "drained_master"
+)
+
+var (
+	nodeUpGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "node_up",
+		Help:      "Shows if the node is reachable (1) or not (0)",
+	}, []string{"node"})
+	nodeRoleGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "node_role",
+		Help:      "The role last configured on the node, 1 for the current role and 0 otherwise",
+	}, []string{"node", "role"})
+	masterInfoGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "master_info",
+		Help:      "Information about the current master, always 1",
+	}, []string{"node", runID})
+	failoversTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "failovers_total",
+		Help:      "Number of times the client switched over to a new master",
+	})
+	replicaofTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "replicaof_commands_total",
+		Help:      "Number of REPLICAOF commands send to the nodes",
+	}, []string{"node"})
+	linkDownGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "replication_link_down",
+		Help:      "Shows if the replication link of a slave is down (1) or not (0)",
+	}, []string{"node"})
+	linkRepairsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "replication_link_repairs_total",
+		Help:      "Number of times the connection of a replica to its master was reset as the replication link stayed down",
+	}, []string{"node"})
+	fencingsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "fencings_total",
+		Help:      "Number of times a former master was fenced before demoting it",
+	}, []string{"node"})
+	switchoverOffsetGapGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "switchover_offset_gap_bytes",
+		Help:      "Number of bytes the new master was behind the old master on the last switchover, negative when it was ahead",
+	})
+	switchoverOffsetGapTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "switchover_offset_gap_bytes_total",
+		Help:      "Number of bytes the new master was behind the old master, summed over all switchovers",
+	})
+	promotionBlockedGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "promotion_blocked",
+		Help:      "Shows if the promotion of an empty node over populated nodes is refused (1) or not (0)",
+	})
+	dryRunPendingGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "dry_run_pending_commands",
+		Help:      "Number of commands not send to the node due to the dry-run mode",
+	}, []string{"node"})
+	reconciliationPausedGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "reconciliation_paused",
+		Help:      "Shows if the reconciliation of the nodes is paused (1) or not (0)",
+	})
+	splitBrainGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "split_brain",
+		Help:      "Shows if a split-brain is detected or latched (1) or not (0)",
+	})
+	splitBrainDetectionsTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "split_brain_detections_total",
+		Help:      "Number of times a split-brain was detected",
+	})
+	switchoverPendingGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "switchover_pending",
+		Help:      "Shows if a new master is waiting to be stable before switching to it (1) or not (0)",
+	})
+	switchoverLimitedGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "switchover_limited",
+		Help:      "Shows if switchovers are refused, because there were too many within the window (1) or not (0)",
+	})
+	nodeDrainedGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "node_drained",
+		Help:      "Shows if the node is drained (1) or not (0)",
+	}, []string{"node"})
+	drainedMasterGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "drained_master",
+		Help:      "Shows if the virtual address is pointing to a drained node (1) or not (0)",
+	})
+	virtualAddressErrorsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "virtual_address_errors_total",
+		Help:      "Number of failed lookups of the master behind the virtual address",
+	}, []string{"reason"})
+)
+
+func init() {
+	prometheus.MustRegister(
+		nodeUpGauge,
+		nodeRoleGauge,
+		masterInfoGauge,
+		failoversTotal,
+		replicaofTotal,
+		linkDownGauge,
+		linkRepairsTotal,
+		fencingsTotal,
+		switchoverOffsetGapGauge,
+		switchoverOffsetGapTotal,
+		promotionBlockedGauge,
+		dryRunPendingGauge,
+		reconciliationPausedGauge,
+		splitBrainGauge,
+		splitBrainDetectionsTotal,
+		switchoverPendingGauge,
+		switchoverLimitedGauge,
+		nodeDrainedGauge,
+		drainedMasterGauge,
+		virtualAddressErrorsTotal,
+	)
+}
+
+// Update the node metrics with the current state of the nodes
+func (c *FailoverClient) updateNodeMetrics() {
+	for _, n := range c.nodes {
+		up := 0.0
+		if n.up {
+			up = 1
+		}
+		nodeUpGauge.WithLabelValues(n.String()).Set(up)
+
+		for _, r := range []string{master, slave} {
+			value := 0.0
+			if n.roleCache.role == r {
+				value = 1
+			}
+			nodeRoleGauge.WithLabelValues(n.String(), r).Set(value)
+		}
+
+		linkDown := 0.0
+		if !n.linkDownSince.IsZero() {
+			linkDown = 1
+		}
+		linkDownGauge.WithLabelValues(n.String()).Set(linkDown)
+
+		drained := 0.0
+		if n.drained {
+			drained = 1
+		}
+		nodeDrainedGauge.WithLabelValues(n.String()).Set(drained)
+	}
+}
+
+// Replace the master info metric with the current master
+func (c *FailoverClient) updateMasterMetrics() {
+	masterInfoGauge.Reset()
+	if c.masterNode != nil {
+		masterInfoGauge.WithLabelValues(c.masterNode.String(), c.currentMaster).Set(1)
+	}
+}
+
+// Update the metric showing if a promotion is refused
+func (c *FailoverClient) updatePromotionMetrics() {
+	value := 0.0
+	if c.blockedPromotion != nil {
+		value = 1
+	}
+	promotionBlockedGauge.Set(value)
+}
+
+// Update the metrics showing if a switchover is pending or limited
+func (c *FailoverClient) updateDampeningMetrics() {
+	pending := 0.0
+	if c.pendingSwitchover != nil {
+		pending = 1
+	}
+	switchoverPendingGauge.Set(pending)
+
+	limited := 0.0
+	if c.switchoverLimited {
+		limited = 1
+	}
+	switchoverLimitedGauge.Set(limited)
+}
+
+// Update the split-brain metric with the current condition.
+// Needs to be called while holding the lock.
+func (c *FailoverClient) updateSplitBrainMetrics() {
+	value := 0.0
+	if c.splitBrain.Active || c.splitBrain.Latched {
+		value = 1
+	}
+	splitBrainGauge.Set(value)
+}
+
+// Remove the metrics of a node that is no longer managed
+func deleteNodeMetrics(n *node) {
+	nodeUpGauge.DeleteLabelValues(n.String())
+	nodeRoleGauge.DeletePartialMatch(prometheus.Labels{"node": n.String()})
+	replicaofTotal.DeleteLabelValues(n.String())
+	linkDownGauge.DeleteLabelValues(n.String())
+	linkRepairsTotal.DeleteLabelValues(n.String())
+	fencingsTotal.DeleteLabelValues(n.String())
+	dryRunPendingGauge.DeleteLabelValues(n.String())
+	nodeDrainedGauge.DeleteLabelValues(n.String())
+}
@@ -0,0 +1,48 @@
+package failoverclient
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateNodeMetrics(t *testing.T) {
+	assert := assert.New(t)
+
+	c := &FailoverClient{
+		nodes: []*node{
+			{address: "metrics-node1", port: 6379, up: true, roleCache: &roleCache{role: master}},
+			{address: "metrics-node2", port: 6379, up: false, roleCache: &roleCache{}, linkDownSince: time.Now()},
+		},
+	}
+
+	c.updateNodeMetrics()
+
+	assert.Equal(1.0, testutil.ToFloat64(nodeUpGauge.WithLabelValues("metrics-node1:6379")), "Node 1 should be up")
+	assert.Equal(0.0, testutil.ToFloat64(nodeUpGauge.WithLabelValues("metrics-node2:6379")), "Node 2 should be down")
+	assert.Equal(1.0, testutil.ToFloat64(nodeRoleGauge.WithLabelValues("metrics-node1:6379", master)), "Node 1 should be master")
+	assert.Equal(0.0, testutil.ToFloat64(nodeRoleGauge.WithLabelValues("metrics-node1:6379", slave)), "Node 1 should not be slave")
+	assert.Equal(0.0, testutil.ToFloat64(nodeRoleGauge.WithLabelValues("metrics-node2:6379", master)), "Node 2 should have no role")
+	assert.Equal(0.0, testutil.ToFloat64(linkDownGauge.WithLabelValues("metrics-node1:6379")), "Node 1 should have no link down")
+	assert.Equal(1.0, testutil.ToFloat64(linkDownGauge.WithLabelValues("metrics-node2:6379")), "Node 2 should have the link down")
+}
+
+func TestUpdateMasterMetrics(t *testing.T) {
+	assert := assert.New(t)
+
+	n := &node{address: "metrics-master", port: 6379}
+	c := &FailoverClient{
+		currentMaster: "testrunid",
+		masterNode:    n,
+	}
+
+	c.updateMasterMetrics()
+	assert.Equal(1, testutil.CollectAndCount(masterInfoGauge), "Should have exactly one master")
+	assert.Equal(1.0, testutil.ToFloat64(masterInfoGauge.WithLabelValues(n.String(), "testrunid")), "Should expose the current master")
+
+	c.masterNode = nil
+	c.updateMasterMetrics()
+	assert.Equal(0, testutil.CollectAndCount(masterInfoGauge), "Should remove the old master")
+}
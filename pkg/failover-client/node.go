@@ -4,30 +4,72 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"net"
+	"strconv"
 	"time"
 
+	valkeyinfo "github.com/heathcliff26/valkey-keepalived/pkg/valkey-info"
 	"github.com/valkey-io/valkey-go"
 )
 
 const (
-	master = "master"
-	slave  = "slave"
+	master = valkeyinfo.RoleMaster
+	slave  = valkeyinfo.RoleSlave
 
-	runID      = "run_id"
-	role       = "role"
-	masterHost = "master_host"
-	masterPort = "master_port"
+	runID = "run_id"
+
+	linkStatusUp   = valkeyinfo.LinkStatusUp
+	linkStatusDown = valkeyinfo.LinkStatusDown
+	linkStatusSync = "sync"
+
+	replicaofNoOneCmd = "REPLICAOF NO ONE"
+	killMasterLinkCmd = "CLIENT KILL TYPE MASTER"
 )
 
 type node struct {
+	name    string
 	address string
 	port    int64
 	runID   string
 	up      bool
 	client  valkey.Client
 
+	// The settings used for connecting to the node
+	connection connectionConfig
+	option     valkey.ClientOption
+	// The credentials replicas use for authenticating against this node
+	replication Credentials
+
+	// The last error encountered when talking to the node
+	lastError     error
+	lastErrorTime time.Time
+
 	// Caches the last successfully set role to reduce api calls
 	roleCache *roleCache
+
+	// The last seen state of the replication link, empty for masters
+	linkStatus string
+	// Since when the replication link is down, zero while it is up
+	linkDownSince time.Time
+	// How long the link may be down before the connection to the master is reset, 0 disables it
+	linkDownTimeout time.Duration
+
+	// How to fence the node when it needs to be demoted from master
+	fencing FencingConfig
+
+	// The replication info retrieved on the current check, nil when unknown or outdated by a change of the role
+	replicationInfo *valkeyinfo.Replication
+	// The last known replication offset and when it was retrieved
+	offset     int64
+	offsetTime time.Time
+
+	// Drained nodes are neither changed nor accepted as master
+	drained bool
+
+	// Only log the commands changing the node instead of sending them
+	dryRun bool
+	// The commands that were not send due to the dry-run mode
+	pending []string
 }
 
 const (
@@ -35,20 +77,25 @@ const (
 	nodeUpMsg   = "Node is UP"
 )
 
+// Return the address of the node in the format host:port
+func (n *node) String() string {
+	return net.JoinHostPort(n.address, strconv.FormatInt(n.port, 10))
+}
+
 // Connect to valkey and retrieve the run_id
-func (n *node) connect(ctx context.Context, option valkey.ClientOption) error {
-	client, err := newValkeyClient(n.address, n.port, option)
+func (n *node) connect(ctx context.Context) error {
+	client, err := newValkeyClient(n.address, n.port, n.option)
 	if err != nil {
 		return err
 	}
 
-	res, err := client.Do(ctx, client.B().Info().Section("server").Build()).ToString()
+	res, err := client.Do(ctx, client.B().Info().Section(valkeyinfo.SectionServer).Build()).ToString()
 	if err != nil {
 		client.Close()
 		return err
 	}
 
-	n.runID = ParseValueFromInfo(res, runID)
+	n.runID = valkeyinfo.Parse(res).Server.RunID
 	n.client = client
 	n.up = true
 
@@ -60,15 +107,19 @@ func (n *node) connect(ctx context.Context, option valkey.ClientOption) error {
 func (n *node) ping(ctx context.Context) {
 	res, err := n.client.Do(ctx, n.client.B().Ping().Build()).ToString()
 	if err != nil || res != "PONG" {
+		if err == nil {
+			err = fmt.Errorf("unexpected ping response \"%s\"", res)
+		}
+		n.setError(err)
 		if n.up {
-			slog.Info(nodeDownMsg, slog.String("node", n.address), "err", err, slog.String("res", res))
+			slog.Info(nodeDownMsg, slog.String("node", n.name), "err", err, slog.String("res", res))
 			n.up = false
 		}
 		n.client.Close()
 		n.client = nil
 	} else if !n.up {
 		n.up = true
-		slog.Info(nodeUpMsg, slog.String("node", n.address))
+		slog.Info(nodeUpMsg, slog.String("node", n.name))
 	}
 }
 
@@ -81,19 +132,29 @@ func (n *node) master(ctx context.Context) error {
 		return nil
 	}
 
-	info, err := n.getReplicationInfo(ctx)
+	repl, err := n.currentReplicationInfo(ctx)
 	if err != nil {
 		return err
 	}
-	if ParseValueFromInfo(info, role) == master {
+	if repl.Role == master {
+		n.resetLink()
+		n.setPending()
 		n.roleCache.Save(master, nil)
 		return nil
 	}
+	n.roleCache.Clear()
+	if n.dryRun {
+		n.setPending(replicaofNoOneCmd)
+		return nil
+	}
 
+	replicaofTotal.WithLabelValues(n.String()).Inc()
 	err = n.client.Do(ctx, n.client.B().Replicaof().No().One().Build()).Error()
 	if err != nil {
 		return err
 	}
+	n.replicationInfo = nil
+	n.resetLink()
 	n.roleCache.Save(master, nil)
 	return nil
 }
@@ -101,34 +162,172 @@ func (n *node) master(ctx context.Context) error {
 // Make this node a slave of the given master
 func (n *node) slave(ctx context.Context, newMaster *node) error {
 	if n.client == nil {
-		slog.Debug("Node is not up, skipping for update", slog.String("node", n.address))
+		slog.Debug("Node is not up, skipping for update", slog.String("node", n.name))
 		return nil
 	}
+	if newMaster == nil {
+		return fmt.Errorf("no master known to replicate from")
+	}
 
 	if n.roleCache.IsSlaveOf(newMaster) {
 		return nil
 	}
 
-	info, err := n.getReplicationInfo(ctx)
+	repl, err := n.currentReplicationInfo(ctx)
 	if err != nil {
 		return err
 	}
-	if infoSlaveOfNode(info, newMaster) {
-		n.roleCache.Save(slave, newMaster)
+	if infoSlaveOfNode(repl, newMaster) {
+		return n.verifyLink(ctx, newMaster, repl)
+	}
+	if n.dryRun {
+		n.setPending(replicaofCmd(newMaster))
 		return nil
 	}
 
+	fenced := false
+	if repl.Role == master && n.fencing.Enabled {
+		err = n.fence(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to fence former master: %w", err)
+		}
+		fenced = true
+	}
+
+	err = n.configureMasterAuth(ctx, newMaster)
+	if err != nil {
+		return err
+	}
+
+	replicaofTotal.WithLabelValues(n.String()).Inc()
 	err = n.client.Do(ctx, n.client.B().Replicaof().Host(newMaster.address).Port(newMaster.port).Build()).Error()
 	if err != nil {
 		return err
 	}
-	n.roleCache.Save(slave, newMaster)
+	n.replicationInfo = nil
+	n.roleCache.Clear()
+	if fenced {
+		n.unfence(ctx)
+	}
+	// The role is only cached once the replication link is verified on the next check
+	n.linkStatus = linkStatusDown
+	n.linkDownSince = time.Now()
+	return nil
+}
+
+// Check that the replication link to the master is up.
+// The role is only cached while the link is up.
+// When the link stays down for longer than the link down timeout, the connection to the master is reset.
+func (n *node) verifyLink(ctx context.Context, newMaster *node, repl valkeyinfo.Replication) error {
+	if repl.MasterLinkStatus == linkStatusUp {
+		if !n.linkDownSince.IsZero() {
+			slog.Info("Replication link is up", slog.String("node", n.name), slog.String("master", newMaster.name))
+		}
+		n.linkStatus = linkStatusUp
+		n.linkDownSince = time.Time{}
+		n.setPending()
+		n.roleCache.Save(slave, newMaster)
+		return nil
+	}
+
+	if repl.MasterSyncInProgress {
+		// Resetting the connection would only restart the sync
+		slog.Debug("Replica is syncing with the master", slog.String("node", n.name), slog.String("master", newMaster.name))
+		n.linkStatus = linkStatusSync
+		n.linkDownSince = time.Time{}
+		n.setPending()
+		return nil
+	}
+
+	n.linkStatus = linkStatusDown
+	if n.linkDownSince.IsZero() {
+		n.linkDownSince = time.Now()
+	}
+
+	// The link might be down due to missing or outdated credentials
+	err := n.configureMasterAuth(ctx, newMaster)
+	if err != nil {
+		return err
+	}
+
+	downFor := time.Since(n.linkDownSince)
+	if n.linkDownTimeout == 0 || downFor < n.linkDownTimeout {
+		slog.Debug("Replication link is not up yet", slog.String("node", n.name), slog.String("master", newMaster.name), slog.Duration("downFor", downFor))
+		n.setPending()
+		return nil
+	}
+	if n.dryRun {
+		n.setPending(killMasterLinkCmd)
+		return nil
+	}
+
+	slog.Warn("Replication link is down for too long, resetting the connection to the master", slog.String("node", n.name), slog.String("master", newMaster.name), slog.Duration("downFor", downFor), slog.Int64("lastIOSecondsAgo", repl.MasterLastIOSecondsAgo))
+	linkRepairsTotal.WithLabelValues(n.String()).Inc()
+
+	// REPLICAOF is ignored when the node already replicates from the master and REPLICAOF NO ONE would briefly make it a writable master.
+	// Dropping the connection makes the replica reconnect on its own and attempt a partial resync.
+	err = n.client.Do(ctx, n.client.B().ClientKill().TypeMaster().Build()).Error()
+	if err != nil {
+		return err
+	}
+	n.linkDownSince = time.Now()
+	return nil
+}
+
+// Return the REPLICAOF command making a node a slave of the given master, as it is logged in dry-run mode
+func replicaofCmd(master *node) string {
+	return fmt.Sprintf("REPLICAOF %s %d", master.address, master.port)
+}
+
+// Forget the state of the replication link
+func (n *node) resetLink() {
+	n.linkStatus = ""
+	n.linkDownSince = time.Time{}
+}
+
+// Configure the credentials used for authenticating against the given master.
+// Does nothing when the master has no credentials configured.
+func (n *node) configureMasterAuth(ctx context.Context, newMaster *node) error {
+	if n.dryRun || !newMaster.replication.isSet() {
+		return nil
+	}
+	creds, err := newMaster.replication.load()
+	if err != nil {
+		return fmt.Errorf("failed to load replication credentials: %w", err)
+	}
+	if creds.Password == "" {
+		return nil
+	}
+
+	cmd := n.client.B().ConfigSet().ParameterValue().ParameterValue("masteruser", creds.Username).ParameterValue("masterauth", creds.Password).Build()
+	err = n.client.Do(ctx, cmd).Error()
+	if err != nil {
+		return fmt.Errorf("failed to configure replication credentials: %w", err)
+	}
 	return nil
 }
 
 // Fetch the replication information from valkey
-func (n *node) getReplicationInfo(ctx context.Context) (string, error) {
-	return n.client.Do(ctx, n.client.B().Info().Section("replication").Build()).ToString()
+func (n *node) getReplicationInfo(ctx context.Context) (valkeyinfo.Replication, error) {
+	res, err := n.client.Do(ctx, n.client.B().Info().Section(valkeyinfo.SectionReplication).Build()).ToString()
+	if err != nil {
+		return valkeyinfo.Replication{}, err
+	}
+	return valkeyinfo.Parse(res).Replication, nil
+}
+
+// Use the replication information retrieved during the current check, only fetch it when unknown
+func (n *node) currentReplicationInfo(ctx context.Context) (valkeyinfo.Replication, error) {
+	if n.replicationInfo != nil {
+		return *n.replicationInfo, nil
+	}
+	return n.getReplicationInfo(ctx)
+}
+
+// Remember the given error as the last error of the node
+func (n *node) setError(err error) {
+	n.lastError = err
+	n.lastErrorTime = time.Now()
 }
 
 // Close the open client
@@ -143,6 +342,7 @@ type roleCache struct {
 	role   string
 	master *node
 
+	ttl    time.Duration
 	expire time.Time
 }
 
@@ -151,7 +351,20 @@ type roleCache struct {
 func (rc *roleCache) Save(role string, master *node) {
 	rc.role = role
 	rc.master = master
-	rc.expire = time.Now().Add(time.Minute)
+	rc.expire = time.Now().Add(rc.ttl)
+}
+
+// Forget the cached role, e.g. after the role was changed
+func (rc *roleCache) Clear() {
+	rc.role = ""
+	rc.master = nil
+	rc.expire = time.Time{}
+}
+
+// Check if master is the last saved role, regardless of the expiry.
+// Used to confirm a promotion right after it, as the cache may be disabled.
+func (rc *roleCache) ConfirmsMaster() bool {
+	return rc.role == master
 }
 
 func (rc *roleCache) isExpired() bool {
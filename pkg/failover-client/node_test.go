@@ -1,10 +1,19 @@
 package failoverclient
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/alicebob/miniredis/v2"
+	"github.com/alicebob/miniredis/v2/server"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/valkey-io/valkey-go"
@@ -17,11 +26,12 @@ func TestNodeConnect(t *testing.T) {
 	n := &node{
 		address: mr.Host(),
 		port:    int64(mr.Server().Addr().Port),
+		option: valkey.ClientOption{
+			DisableCache: true,
+			DisableRetry: true,
+		},
 	}
-	err := n.connect(t.Context(), valkey.ClientOption{
-		DisableCache: true,
-		DisableRetry: true,
-	})
+	err := n.connect(t.Context())
 
 	assert.Equal("section (server) is not supported", err.Error(), "Should return miniredis error")
 	assert.Nil(n.client, "Should not set client")
@@ -89,7 +99,7 @@ func TestNodeMaster(t *testing.T) {
 		mr.Close()
 
 		assert.Error(n.master(t.Context()), "Should not hit the cache and error out instead")
-		assert.Empty(n.roleCache, "Should not save to cache on error")
+		assert.Empty(n.roleCache.role, "Should not save to cache on error")
 	})
 }
 
@@ -101,16 +111,17 @@ func TestNodeSlave(t *testing.T) {
 	})
 	t.Run("MasterNil", func(t *testing.T) {
 		assert := assert.New(t)
-		require := require.New(t)
-
-		mr, n, err := newNodeWithMiniredis(t)
-		require.NoError(err, "Should create node with client")
-		mr.Close()
 
+		f := newFakeValkey(t, "slave")
+		n := f.newNode(t)
 		n.roleCache.Save(slave, &node{address: "testmaster", port: 6379})
+
+		var err error
 		assert.NotPanics(func() {
-			_ = n.slave(t.Context(), nil)
+			err = n.slave(t.Context(), nil)
 		}, "Should not panic when new master is nil")
+		assert.Error(err, "Should fail without master")
+		assert.Empty(f.receivedCommands(), "Should not send any command")
 	})
 	t.Run("CacheHit", func(t *testing.T) {
 		assert := assert.New(t)
@@ -147,7 +158,152 @@ func TestNodeSlave(t *testing.T) {
 		mr.Close()
 
 		assert.Error(n.slave(t.Context(), &node{}), "Should not hit the cache and error out instead")
-		assert.Empty(n.roleCache, "Should not save to cache on error")
+		assert.Empty(n.roleCache.role, "Should not save to cache on error")
+	})
+}
+
+func TestNodeSlaveMasterAuth(t *testing.T) {
+	t.Run("NoCredentials", func(t *testing.T) {
+		assert := assert.New(t)
+
+		f := newFakeValkey(t, "slave")
+		n := f.newNode(t)
+		m := &node{name: "master", address: "master", port: 6379}
+
+		assert.NoError(n.slave(t.Context(), m), "Should set node to slave")
+		assert.Equal([]string{"REPLICAOF master 6379"}, f.receivedCommands(), "Should only send REPLICAOF")
+		assert.Empty(n.roleCache.role, "Should not save role before the link is up")
+	})
+	t.Run("WithCredentials", func(t *testing.T) {
+		assert := assert.New(t)
+
+		f := newFakeValkey(t, "slave")
+		n := f.newNode(t)
+		m := &node{name: "master", address: "master", port: 6379, replication: Credentials{Username: "repl", Password: "secret"}}
+
+		assert.NoError(n.slave(t.Context(), m), "Should set node to slave")
+		assert.Equal([]string{"CONFIG SET masteruser repl masterauth secret", "REPLICAOF master 6379"}, f.receivedCommands(), "Should configure credentials before REPLICAOF")
+		assert.Empty(n.roleCache.role, "Should not save role before the link is up")
+
+		f.setSlaveOf(m, linkStatusUp)
+		assert.NoError(n.slave(t.Context(), m), "Should verify node")
+		assert.Equal(slave, n.roleCache.role, "Should save role once the link is up")
+		assert.Len(f.receivedCommands(), 2, "Should not send further commands")
+	})
+	t.Run("LinkDown", func(t *testing.T) {
+		assert := assert.New(t)
+
+		f := newFakeValkey(t, "slave")
+		n := f.newNode(t)
+		m := &node{name: "master", address: "master", port: 6379, replication: Credentials{Password: "secret"}}
+		f.setSlaveOf(m, "down")
+
+		assert.NoError(n.slave(t.Context(), m), "Should not fail")
+		assert.Equal([]string{"CONFIG SET masteruser  masterauth secret"}, f.receivedCommands(), "Should only update the credentials")
+		assert.Empty(n.roleCache.role, "Should not save role while the link is down")
+	})
+	t.Run("PasswordFile", func(t *testing.T) {
+		assert := assert.New(t)
+
+		f := newFakeValkey(t, "slave")
+		n := f.newNode(t)
+		path := filepath.Join(t.TempDir(), "password")
+		require.NoError(t, os.WriteFile(path, []byte("filesecret\n"), 0600), "Should write password file")
+		m := &node{name: "master", address: "master", port: 6379, replication: Credentials{PasswordFile: path}}
+
+		assert.NoError(n.slave(t.Context(), m), "Should set node to slave")
+		assert.Equal("filesecret", f.config["masterauth"], "Should read password from file")
+	})
+	t.Run("MissingPasswordFile", func(t *testing.T) {
+		assert := assert.New(t)
+
+		f := newFakeValkey(t, "slave")
+		n := f.newNode(t)
+		m := &node{name: "master", address: "master", port: 6379, replication: Credentials{PasswordFile: "/not/existing"}}
+
+		assert.Error(n.slave(t.Context(), m), "Should fail to read password")
+		assert.Empty(f.receivedCommands(), "Should not send REPLICAOF without credentials")
+	})
+}
+
+func TestNodeSlaveLinkVerification(t *testing.T) {
+	newSlave := func(t *testing.T, linkStatus string) (*fakeValkey, *node, *node) {
+		f := newFakeValkey(t, "slave")
+		n := f.newNode(t)
+		n.linkDownTimeout = time.Minute
+		m := &node{name: "master", address: "master", port: 6379}
+		f.setSlaveOf(m, linkStatus)
+		return f, n, m
+	}
+
+	t.Run("LinkUp", func(t *testing.T) {
+		assert := assert.New(t)
+
+		_, n, m := newSlave(t, linkStatusUp)
+		n.linkDownSince = time.Now().Add(-time.Hour)
+
+		assert.NoError(n.slave(t.Context(), m), "Should verify node")
+		assert.Equal(slave, n.roleCache.role, "Should save role in cache")
+		assert.Equal(linkStatusUp, n.linkStatus, "Should save link status")
+		assert.Zero(n.linkDownSince, "Should reset the link down time")
+	})
+	t.Run("WithinTimeout", func(t *testing.T) {
+		assert := assert.New(t)
+
+		f, n, m := newSlave(t, linkStatusDown)
+
+		assert.NoError(n.slave(t.Context(), m), "Should not fail")
+		assert.Empty(f.receivedCommands(), "Should not send REPLICAOF")
+		assert.Empty(n.roleCache.role, "Should not save role in cache")
+		assert.Equal(linkStatusDown, n.linkStatus, "Should save link status")
+		assert.NotZero(n.linkDownSince, "Should remember since when the link is down")
+	})
+	t.Run("Repair", func(t *testing.T) {
+		assert := assert.New(t)
+
+		f, n, m := newSlave(t, linkStatusDown)
+		n.linkDownSince = time.Now().Add(-2 * time.Minute)
+		repairs := testutil.ToFloat64(linkRepairsTotal.WithLabelValues(n.String()))
+
+		assert.NoError(n.slave(t.Context(), m), "Should repair the link")
+		assert.Equal([]string{"CLIENT KILL TYPE MASTER"}, f.receivedCommands(), "Should reset the connection to the master")
+		assert.NotContains(f.receivedCommands(), "REPLICAOF NO ONE", "Should never promote the replica")
+		assert.Less(time.Since(n.linkDownSince), time.Minute, "Should restart the timeout")
+		assert.Equal(repairs+1, testutil.ToFloat64(linkRepairsTotal.WithLabelValues(n.String())), "Should count the repair")
+	})
+	t.Run("Disabled", func(t *testing.T) {
+		assert := assert.New(t)
+
+		f, n, m := newSlave(t, linkStatusDown)
+		n.linkDownTimeout = 0
+		n.linkDownSince = time.Now().Add(-time.Hour)
+
+		assert.NoError(n.slave(t.Context(), m), "Should not fail")
+		assert.Empty(f.receivedCommands(), "Should not send REPLICAOF")
+	})
+	t.Run("SyncInProgress", func(t *testing.T) {
+		assert := assert.New(t)
+
+		f, n, m := newSlave(t, linkStatusDown)
+		f.setSyncing()
+		n.linkDownSince = time.Now().Add(-time.Hour)
+
+		assert.NoError(n.slave(t.Context(), m), "Should not fail")
+		assert.Empty(f.receivedCommands(), "Should not interrupt the sync")
+		assert.Equal(linkStatusSync, n.linkStatus, "Should save link status")
+		assert.Zero(n.linkDownSince, "Should not count the sync as down")
+		assert.Empty(n.roleCache.role, "Should not save role in cache")
+	})
+	t.Run("Promoted", func(t *testing.T) {
+		assert := assert.New(t)
+
+		_, n, _ := newSlave(t, linkStatusDown)
+		n.linkStatus = linkStatusDown
+		n.linkDownSince = time.Now()
+
+		assert.NoError(n.master(t.Context()), "Should promote node")
+		assert.Empty(n.linkStatus, "Should reset the link status")
+		assert.Zero(n.linkDownSince, "Should reset the link down time")
 	})
 }
 
@@ -155,7 +311,7 @@ func TestNodeCacheSave(t *testing.T) {
 	_, c := newSetupAndClient(t, "node-cache-save", 2)
 
 	for i, n := range c.nodes {
-		err := n.connect(t.Context(), valkey.ClientOption{})
+		err := n.connect(t.Context())
 		require.NoErrorf(t, err, "Should connect to node %d", i)
 	}
 
@@ -177,13 +333,44 @@ func TestNodeCacheSave(t *testing.T) {
 		err := n.slave(t.Context(), c.nodes[0])
 
 		assert.NoError(err, "Should set node to slave")
-		assert.Equal(slave, n.roleCache.role, "Should save role in cache")
+		assert.Empty(n.roleCache.role, "Should not save role before the link is up")
+
+		assert.Eventually(func() bool {
+			return n.slave(t.Context(), c.nodes[0]) == nil && n.roleCache.role == slave
+		}, waitTimeout, checkIntervall, "Should save role in cache once the link is up")
 		assert.Equal(c.nodes[0], n.roleCache.master, "Should save master_host in cache")
 		assert.NotEmpty(n.roleCache.expire, "Should set expire time")
 		assert.False(n.roleCache.isExpired(), "Cache should not be expired")
 	})
 }
 
+func TestNodeCacheClear(t *testing.T) {
+	t.Run("Slave", func(t *testing.T) {
+		assert := assert.New(t)
+
+		f := newFakeValkey(t, "runid1")
+		n := f.newNode(t)
+		n.roleCache.Save(master, nil)
+
+		assert.NoError(n.slave(t.Context(), &node{address: "master", port: 6379}), "Should demote the node")
+		assert.False(n.roleCache.ConfirmsMaster(), "Should forget the cached master role")
+		assert.False(n.roleCache.IsMaster(), "Should promote the node again when needed")
+	})
+	t.Run("DryRunMaster", func(t *testing.T) {
+		assert := assert.New(t)
+
+		f := newFakeValkey(t, "runid1")
+		f.setSlaveOf(&node{address: "master", port: 6379}, linkStatusUp)
+		n := f.newNode(t)
+		n.dryRun = true
+		n.roleCache.ttl = 0
+		n.roleCache.Save(master, nil)
+
+		assert.NoError(n.master(t.Context()), "Should succeed in dry-run mode")
+		assert.False(n.roleCache.ConfirmsMaster(), "Should not confirm a stale master role")
+	})
+}
+
 func newNodeWithMiniredis(t *testing.T) (*miniredis.Miniredis, *node, error) {
 	mr := miniredis.RunT(t)
 
@@ -201,8 +388,233 @@ func newNodeWithMiniredis(t *testing.T) (*miniredis.Miniredis, *node, error) {
 		address:   mr.Host(),
 		port:      int64(mr.Server().Addr().Port),
 		client:    client,
-		roleCache: &roleCache{},
+		roleCache: &roleCache{ttl: DEFAULT_ROLE_CACHE_TTL},
 	}
 
 	return mr, n, nil
 }
+
+func TestNodeString(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("node1:6379", (&node{address: "node1", port: 6379}).String())
+	assert.Equal("[2001:db8::1]:6380", (&node{address: "2001:db8::1", port: 6380}).String(), "Should wrap IPv6 addresses")
+}
+
+// Simulates the replication commands of valkey on top of miniredis
+type fakeValkey struct {
+	mr *miniredis.Miniredis
+
+	lock       sync.Mutex
+	runID      string
+	role       string
+	masterHost string
+	masterPort int64
+	linkStatus string
+	syncing    bool
+	loading    bool
+	keys       int64
+	offset     int64
+	config     map[string]string
+	commands   []string
+	// Number of INFO replication requests
+	infoRequests int
+	// Reply to REPLICAOF with an error
+	failReplicaof bool
+}
+
+func newFakeValkey(t *testing.T, runID string) *fakeValkey {
+	f := &fakeValkey{
+		mr:     miniredis.RunT(t),
+		runID:  runID,
+		role:   master,
+		config: make(map[string]string),
+	}
+	f.mr.Server().SetPreHook(f.hook)
+	return f
+}
+
+func (f *fakeValkey) hook(c *server.Peer, cmd string, args ...string) bool {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	switch cmd {
+	case "INFO":
+		if len(args) == 0 {
+			return false
+		}
+		var res strings.Builder
+		for _, section := range args {
+			switch strings.ToLower(section) {
+			case "server":
+				res.WriteString(fmt.Sprintf("# Server\r\nrun_id:%s\r\n", f.runID))
+			case "persistence":
+				loading := 0
+				if f.loading {
+					loading = 1
+				}
+				res.WriteString(fmt.Sprintf("# Persistence\r\nloading:%d\r\n", loading))
+			case "replication":
+				f.infoRequests++
+				res.WriteString(f.replicationInfo())
+			case "keyspace":
+				res.WriteString("# Keyspace\r\n")
+				if f.keys > 0 {
+					res.WriteString(fmt.Sprintf("db0:keys=%d,expires=0,avg_ttl=0\r\n", f.keys))
+				}
+			default:
+				return false
+			}
+		}
+		c.WriteBulk(res.String())
+	case "REPLICAOF":
+		f.commands = append(f.commands, cmd+" "+strings.Join(args, " "))
+		if f.failReplicaof {
+			c.WriteError("ERR REPLICAOF failed")
+			return true
+		}
+		port, _ := strconv.ParseInt(args[len(args)-1], 10, 64)
+		switch {
+		case strings.ToUpper(args[0]) == "NO":
+			f.role = master
+			f.masterHost = ""
+			f.masterPort = 0
+		case f.role == slave && f.masterHost == args[0] && f.masterPort == port:
+			// Valkey ignores the command when already connected to the master
+		default:
+			f.role = slave
+			f.masterHost = args[0]
+			f.masterPort = port
+			f.linkStatus = linkStatusDown
+		}
+		c.WriteOK()
+	case "CLIENT":
+		switch strings.ToUpper(args[0]) {
+		case "PAUSE", "UNPAUSE":
+			c.WriteOK()
+		case "KILL":
+			c.WriteInt(0)
+		default:
+			return false
+		}
+		f.commands = append(f.commands, cmd+" "+strings.Join(args, " "))
+	case "CONFIG":
+		f.commands = append(f.commands, cmd+" "+strings.Join(args, " "))
+		if strings.ToUpper(args[0]) != "SET" {
+			return false
+		}
+		for i := 1; i+1 < len(args); i += 2 {
+			f.config[args[i]] = args[i+1]
+		}
+		c.WriteOK()
+	default:
+		return false
+	}
+	return true
+}
+
+func (f *fakeValkey) replicationInfo() string {
+	if f.role == master {
+		return fmt.Sprintf("# Replication\r\nrole:master\r\nconnected_slaves:0\r\nmaster_repl_offset:%d\r\n", f.offset)
+	}
+	lastIO, syncing := -1, 0
+	if f.linkStatus == linkStatusUp {
+		lastIO = 0
+	}
+	if f.syncing {
+		syncing = 1
+	}
+	return fmt.Sprintf("# Replication\r\nrole:slave\r\nmaster_host:%s\r\nmaster_port:%d\r\nmaster_link_status:%s\r\nmaster_last_io_seconds_ago:%d\r\nmaster_sync_in_progress:%d\r\nslave_repl_offset:%d\r\nmaster_repl_offset:%d\r\n", f.masterHost, f.masterPort, f.linkStatus, lastIO, syncing, f.offset, f.offset)
+}
+
+// Make the fake a slave of the given node
+func (f *fakeValkey) setSlaveOf(n *node, linkStatus string) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.role = slave
+	f.masterHost = n.address
+	f.masterPort = n.port
+	f.linkStatus = linkStatus
+	f.syncing = false
+}
+
+// Set the size of the dataset
+func (f *fakeValkey) setDataset(keys, offset int64) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.keys = keys
+	f.offset = offset
+}
+
+// Mark the fake as currently syncing with its master
+func (f *fakeValkey) setSyncing() {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.syncing = true
+}
+
+// Let all following REPLICAOF commands fail
+func (f *fakeValkey) setFailReplicaof() {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.failReplicaof = true
+}
+
+// Mark the fake as currently loading its dataset
+func (f *fakeValkey) setLoading() {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.loading = true
+}
+
+// Return the commands changing the replication received so far
+func (f *fakeValkey) receivedCommands() []string {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return slices.Clone(f.commands)
+}
+
+// Return how often the replication info was requested
+func (f *fakeValkey) replicationInfoRequests() int {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.infoRequests
+}
+
+// Create a connected node for the fake
+func (f *fakeValkey) newNode(t *testing.T) *node {
+	n := &node{
+		address: f.mr.Host(),
+		port:    int64(f.mr.Server().Addr().Port),
+		option: valkey.ClientOption{
+			DisableCache: true,
+			DisableRetry: true,
+		},
+		roleCache: &roleCache{ttl: DEFAULT_ROLE_CACHE_TTL},
+	}
+	n.name = n.String()
+	require.NoError(t, n.connect(t.Context()), "Should connect to fake valkey")
+	t.Cleanup(n.close)
+	return n
+}
+
+// Create a client with a connected node for every given run id.
+// The virtual address points to the first node.
+func newFakeCluster(t *testing.T, cfg ValkeyConfig, runIDs ...string) (*FailoverClient, []*fakeValkey) {
+	c := &FailoverClient{
+		timeout: DEFAULT_TIMEOUT,
+		cfg:     cfg,
+	}
+	fakes := make([]*fakeValkey, 0, len(runIDs))
+	for _, runID := range runIDs {
+		f := newFakeValkey(t, runID)
+		n := f.newNode(t)
+		n.dryRun = cfg.DryRun
+		fakes = append(fakes, f)
+		c.nodes = append(c.nodes, n)
+	}
+	c.virtualAddress = c.nodes[0].address
+	c.port = c.nodes[0].port
+	c.clientOption = c.nodes[0].option
+	return c, fakes
+}
@@ -0,0 +1,70 @@
+package failoverclient
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// A switch of the master behind the virtual address
+type Switchover struct {
+	Time time.Time `json:"time"`
+	// The previous master, empty when there was none
+	From string `json:"from,omitempty"`
+	To   string `json:"to"`
+	// The last known replication offsets of both nodes
+	FromOffset     int64     `json:"fromOffset"`
+	FromOffsetTime time.Time `json:"fromOffsetTime,omitzero"`
+	ToOffset       int64     `json:"toOffset"`
+	ToOffsetTime   time.Time `json:"toOffsetTime,omitzero"`
+	// The number of bytes the new master was behind the old one, nil when unknown.
+	// Negative when the new master was ahead.
+	OffsetGap *int64 `json:"offsetGap,omitempty"`
+}
+
+// Fetch the replication info of the node and remember it together with the current offset.
+// Keeps the last known offset when the node can't be reached.
+func (n *node) updateReplicationInfo(ctx context.Context) {
+	repl, err := n.getReplicationInfo(ctx)
+	if err != nil {
+		n.replicationInfo = nil
+		slog.Debug("Failed to retrieve replication info", slog.String("node", n.name), "err", err)
+		return
+	}
+	n.replicationInfo = &repl
+	n.offset = repl.Offset()
+	n.offsetTime = time.Now()
+}
+
+// Remember the switch from the old to the new master and report how much data may have been lost
+func (c *FailoverClient) recordSwitchover(oldMaster, newMaster *node) {
+	res := &Switchover{
+		Time:         time.Now(),
+		To:           newMaster.name,
+		ToOffset:     newMaster.offset,
+		ToOffsetTime: newMaster.offsetTime,
+	}
+	c.lastSwitchover = res
+
+	if oldMaster == nil {
+		return
+	}
+	res.From = oldMaster.name
+	res.FromOffset = oldMaster.offset
+	res.FromOffsetTime = oldMaster.offsetTime
+
+	if oldMaster.offsetTime.IsZero() || newMaster.offsetTime.IsZero() {
+		slog.Warn("Replication offsets are unknown, can't determine data loss of switchover", slog.String("from", res.From), slog.String("to", res.To))
+		return
+	}
+
+	gap := res.FromOffset - res.ToOffset
+	res.OffsetGap = &gap
+	switchoverOffsetGapGauge.Set(float64(gap))
+	if gap > 0 {
+		switchoverOffsetGapTotal.Add(float64(gap))
+		slog.Warn("New master was behind the old master, writes may have been lost", slog.String("from", res.From), slog.String("to", res.To), slog.Int64("fromOffset", res.FromOffset), slog.Int64("toOffset", res.ToOffset), slog.Int64("offsetGap", gap), slog.Duration("fromOffsetAge", time.Since(res.FromOffsetTime)))
+	} else {
+		slog.Info("New master was not behind the old master", slog.String("from", res.From), slog.String("to", res.To), slog.Int64("fromOffset", res.FromOffset), slog.Int64("toOffset", res.ToOffset), slog.Int64("offsetGap", gap))
+	}
+}
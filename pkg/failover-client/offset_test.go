@@ -0,0 +1,123 @@
+package failoverclient
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNodeUpdateReplicationInfo(t *testing.T) {
+	assert := assert.New(t)
+
+	f := newFakeValkey(t, "runid1")
+	n := f.newNode(t)
+
+	f.setDataset(0, 100)
+	n.updateReplicationInfo(t.Context())
+	assert.Equal(int64(100), n.offset, "Should use the offset of the master")
+	assert.NotZero(n.offsetTime, "Should remember when the offset was retrieved")
+	if assert.NotNil(n.replicationInfo, "Should remember the replication info") {
+		assert.Equal(master, n.replicationInfo.Role, "Should contain the role")
+	}
+
+	f.setSlaveOf(&node{address: "master", port: 6379}, linkStatusUp)
+	f.setDataset(0, 150)
+	n.updateReplicationInfo(t.Context())
+	assert.Equal(int64(150), n.offset, "Should use the offset of the slave")
+
+	offsetTime := n.offsetTime
+	f.mr.Close()
+	n.updateReplicationInfo(t.Context())
+	assert.Equal(int64(150), n.offset, "Should keep the last known offset when unreachable")
+	assert.Equal(offsetTime, n.offsetTime, "Should keep the time of the last known offset")
+	assert.Nil(n.replicationInfo, "Should forget the replication info when unreachable")
+}
+
+func TestRecordSwitchover(t *testing.T) {
+	t.Run("InitialMaster", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		c := &FailoverClient{}
+		newMaster := &node{name: "new"}
+
+		c.recordSwitchover(nil, newMaster)
+
+		require.NotNil(c.lastSwitchover, "Should remember the switchover")
+		assert.Empty(c.lastSwitchover.From, "Should have no old master")
+		assert.Equal("new", c.lastSwitchover.To, "Should contain the new master")
+		assert.Nil(c.lastSwitchover.OffsetGap, "Should not calculate a gap")
+	})
+	t.Run("UnknownOffset", func(t *testing.T) {
+		c := &FailoverClient{}
+		c.recordSwitchover(&node{name: "old"}, &node{name: "new", offset: 10, offsetTime: time.Now()})
+
+		assert.Nil(t, c.lastSwitchover.OffsetGap, "Should not calculate a gap without the offset of the old master")
+	})
+	t.Run("NewMasterBehind", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		c := &FailoverClient{}
+		oldMaster := &node{name: "old", offset: 150, offsetTime: time.Now()}
+		newMaster := &node{name: "new", offset: 100, offsetTime: time.Now()}
+		total := testutil.ToFloat64(switchoverOffsetGapTotal)
+
+		c.recordSwitchover(oldMaster, newMaster)
+
+		res := c.lastSwitchover
+		require.NotNil(res.OffsetGap, "Should calculate the gap")
+		assert.Equal(int64(50), *res.OffsetGap, "Should contain the gap")
+		assert.Equal("old", res.From, "Should contain the old master")
+		assert.Equal(int64(150), res.FromOffset, "Should contain the offset of the old master")
+		assert.Equal(int64(100), res.ToOffset, "Should contain the offset of the new master")
+		assert.Equal(50.0, testutil.ToFloat64(switchoverOffsetGapGauge), "Should expose the gap")
+		assert.Equal(total+50, testutil.ToFloat64(switchoverOffsetGapTotal), "Should add the gap to the total")
+	})
+	t.Run("NewMasterAhead", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		c := &FailoverClient{}
+		oldMaster := &node{name: "old", offset: 100, offsetTime: time.Now()}
+		newMaster := &node{name: "new", offset: 120, offsetTime: time.Now()}
+		total := testutil.ToFloat64(switchoverOffsetGapTotal)
+
+		c.recordSwitchover(oldMaster, newMaster)
+
+		require.NotNil(c.lastSwitchover.OffsetGap, "Should calculate the gap")
+		assert.Equal(int64(-20), *c.lastSwitchover.OffsetGap, "Should contain the negative gap")
+		assert.Equal(-20.0, testutil.ToFloat64(switchoverOffsetGapGauge), "Should expose the gap")
+		assert.Equal(total, testutil.ToFloat64(switchoverOffsetGapTotal), "Should not add to the total")
+	})
+}
+
+func TestReconcileSwitchoverOffsetGap(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	c, fakes := newFakeCluster(t, ValkeyConfig{}, "runid1", "runid2")
+	fakes[0].setDataset(10, 200)
+	fakes[1].setSlaveOf(c.nodes[0], linkStatusUp)
+	fakes[1].setDataset(10, 180)
+
+	require.True(c.reconcile(), "Should resolve the initial master")
+
+	// The virtual address moves to node 1 while the old master is gone
+	fakes[0].mr.Close()
+	c.virtualAddress = c.nodes[1].address
+	c.port = c.nodes[1].port
+	require.True(c.reconcile(), "Should switch to the new master")
+
+	c.recordIteration(true)
+	res := c.Status().LastSwitchover
+	require.NotNil(res, "Should expose the switchover in the status")
+	assert.Equal(c.nodes[0].name, res.From, "Should contain the old master")
+	assert.Equal(c.nodes[1].name, res.To, "Should contain the new master")
+	require.NotNil(res.OffsetGap, "Should calculate the gap from the last known offsets")
+	assert.Equal(int64(20), *res.OffsetGap, "Should contain the gap")
+	assert.Equal(int64(200), c.Status().Nodes[0].ReplicationOffset, "Should keep the last known offset of the old master")
+}
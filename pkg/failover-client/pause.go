@@ -0,0 +1,98 @@
+package failoverclient
+
+import (
+	"log/slog"
+	"os"
+	"syscall"
+	"time"
+)
+
+// The state of a paused reconciliation
+type Pause struct {
+	Since time.Time `json:"since"`
+	// When the pause expires, zero when it is kept until resumed
+	Until time.Time `json:"until,omitzero"`
+}
+
+// Pause the reconciliation of the nodes, the nodes are still checked and reported on.
+// Expires after the given duration, 0 uses the configured pause timeout.
+// The duration is capped at the pause timeout, unless it is disabled.
+// Pausing again while paused extends the pause.
+func (c *FailoverClient) Pause(d time.Duration) Pause {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if d <= 0 {
+		d = c.pauseTimeout
+	} else if c.pauseTimeout > 0 && d > c.pauseTimeout {
+		slog.Warn("Requested pause is longer than the pause timeout, using the pause timeout instead", slog.Duration("requested", d), slog.Duration("pauseTimeout", c.pauseTimeout))
+		d = c.pauseTimeout
+	}
+
+	res := Pause{Since: time.Now()}
+	if c.pause != nil {
+		res.Since = c.pause.Since
+	}
+	if d > 0 {
+		res.Until = time.Now().Add(d)
+	}
+	c.pause = &res
+
+	slog.Warn("Pausing reconciliation, the nodes will not be changed until resumed", slog.Time("until", res.Until))
+	reconciliationPausedGauge.Set(1)
+	return res
+}
+
+// Resume the reconciliation of the nodes
+func (c *FailoverClient) Resume() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.pause == nil {
+		return
+	}
+	slog.Info("Resuming reconciliation", slog.Duration("pausedFor", time.Since(c.pause.Since)))
+	c.pause = nil
+	reconciliationPausedGauge.Set(0)
+}
+
+// Return the current pause, nil when not paused
+func (c *FailoverClient) Paused() *Pause {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.currentPause()
+}
+
+// Check if the reconciliation is paused, resumes when the pause expired
+func (c *FailoverClient) reconciliationPaused() bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.pause != nil && !c.pause.Until.IsZero() && time.Now().After(c.pause.Until) {
+		slog.Info("Pause expired, resuming reconciliation", slog.Duration("pausedFor", time.Since(c.pause.Since)))
+		c.pause = nil
+		reconciliationPausedGauge.Set(0)
+	}
+	return c.pause != nil
+}
+
+// Return a copy of the current pause.
+// Needs to be called while holding the lock.
+func (c *FailoverClient) currentPause() *Pause {
+	if c.pause == nil {
+		return nil
+	}
+	res := *c.pause
+	return &res
+}
+
+// Pause on SIGUSR1 and resume on SIGUSR2
+func (c *FailoverClient) handleMaintenanceSignal(sig os.Signal) {
+	switch sig {
+	case syscall.SIGUSR1:
+		c.Pause(0)
+	case syscall.SIGUSR2:
+		c.Resume()
+	}
+}
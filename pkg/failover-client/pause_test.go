@@ -0,0 +1,125 @@
+package failoverclient
+
+import (
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPauseResume(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	c := &FailoverClient{pauseTimeout: time.Hour}
+
+	assert.Nil(c.Paused(), "Should not be paused initially")
+	assert.False(c.reconciliationPaused(), "Should not be paused initially")
+
+	res := c.Pause(0)
+	assert.NotZero(res.Since, "Should remember when paused")
+	assert.WithinDuration(time.Now().Add(time.Hour), res.Until, time.Minute, "Should use the configured pause timeout")
+	assert.True(c.reconciliationPaused(), "Should be paused")
+	assert.Equal(1.0, testutil.ToFloat64(reconciliationPausedGauge), "Should expose the pause")
+	require.NotNil(c.Status().Paused, "Should show the pause in the status immediately")
+
+	extended := c.Pause(2 * time.Hour)
+	assert.Equal(res.Since, extended.Since, "Should keep the start of the pause")
+	assert.True(extended.Until.After(res.Until), "Should extend the pause")
+	assert.WithinDuration(time.Now().Add(time.Hour), extended.Until, time.Minute, "Should cap the duration at the pause timeout")
+
+	shorter := c.Pause(10 * time.Minute)
+	assert.WithinDuration(time.Now().Add(10*time.Minute), shorter.Until, time.Minute, "Should use shorter durations as given")
+
+	c.Resume()
+	assert.Nil(c.Paused(), "Should resume")
+	assert.False(c.reconciliationPaused(), "Should not be paused anymore")
+	assert.Equal(0.0, testutil.ToFloat64(reconciliationPausedGauge), "Should clear the metric")
+
+	c.pauseTimeout = 0
+	assert.Zero(c.Pause(0).Until, "Should not expire without pause timeout")
+	assert.WithinDuration(time.Now().Add(2*time.Hour), c.Pause(2*time.Hour).Until, time.Minute, "Should not cap the duration without pause timeout")
+	c.Resume()
+}
+
+func TestPauseExpiry(t *testing.T) {
+	assert := assert.New(t)
+
+	c := &FailoverClient{}
+	c.Pause(time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+
+	assert.NotNil(c.Paused(), "Should keep the pause until the next check")
+	assert.False(c.reconciliationPaused(), "Should resume once expired")
+	assert.Nil(c.Paused(), "Should clear the pause")
+}
+
+func TestHandleMaintenanceSignal(t *testing.T) {
+	assert := assert.New(t)
+
+	c := &FailoverClient{}
+
+	c.handleMaintenanceSignal(syscall.SIGUSR1)
+	assert.NotNil(c.Paused(), "Should pause on SIGUSR1")
+
+	c.handleMaintenanceSignal(syscall.SIGUSR2)
+	assert.Nil(c.Paused(), "Should resume on SIGUSR2")
+}
+
+func TestReconcilePaused(t *testing.T) {
+	assert := assert.New(t)
+
+	c, fakes := newFakeCluster(t, ValkeyConfig{}, "runid1", "runid2")
+
+	c.Pause(0)
+	assert.True(c.reconcile(), "Should still be ready while paused")
+	assert.Nil(c.masterNode, "Should not switch to the master while paused")
+	assert.Empty(fakes[1].receivedCommands(), "Should not change the nodes while paused")
+
+	c.Resume()
+	assert.True(c.reconcile(), "Should reconcile after resume")
+	assert.Same(c.nodes[0], c.masterNode, "Should switch to the master after resume")
+	assert.NotEmpty(fakes[1].receivedCommands(), "Should change the nodes after resume")
+}
+
+func TestReconcilePausedSwitchover(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	c, _ := newFakeCluster(t, ValkeyConfig{}, "runid1", "runid2")
+
+	require.True(c.reconcile(), "Should reconcile the initial master")
+	require.Same(c.nodes[0], c.masterNode, "Should use the initial master")
+
+	c.Pause(0)
+	c.port = c.nodes[1].port
+	failovers := testutil.ToFloat64(failoversTotal)
+
+	assert.True(c.reconcile(), "Should still be ready while paused")
+	assert.Same(c.nodes[0], c.masterNode, "Should keep the master while paused")
+	assert.Equal("runid1", c.currentMaster, "Should keep the run id of the master while paused")
+	assert.Equal(failovers, testutil.ToFloat64(failoversTotal), "Should not count a failover while paused")
+
+	c.Resume()
+	assert.True(c.reconcile(), "Should reconcile after resume")
+	assert.Same(c.nodes[1], c.masterNode, "Should switch over after resume")
+	assert.Equal(failovers+1, testutil.ToFloat64(failoversTotal), "Should count the failover after resume")
+}
+
+func TestReconcilePausedUnknownMaster(t *testing.T) {
+	assert := assert.New(t)
+
+	c, _ := newFakeCluster(t, ValkeyConfig{}, "runid1", "runid2")
+	unknown := newFakeValkey(t, "unknown")
+	c.port = int64(unknown.mr.Server().Addr().Port)
+	errors := testutil.ToFloat64(virtualAddressErrorsTotal.WithLabelValues(vaErrorUnknownMaster))
+
+	c.Pause(0)
+	assert.False(c.reconcile(), "Should not be ready when the master behind the virtual address is unknown")
+	assert.Nil(c.masterNode, "Should not switch to an unknown master")
+	assert.Equal(errors+1, testutil.ToFloat64(virtualAddressErrorsTotal.WithLabelValues(vaErrorUnknownMaster)), "Should count the unknown master")
+	c.Resume()
+}
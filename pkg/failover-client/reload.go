@@ -0,0 +1,149 @@
+package failoverclient
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"syscall"
+
+	"github.com/valkey-io/valkey-go"
+)
+
+// Loads and validates the configuration from its source.
+// Also returns a function applying the settings outside of the client, e.g. the log level.
+// It is only called once the configuration has been applied to the client and can be nil.
+type ConfigLoader func() (ValkeyConfig, func(), error)
+
+// Set the function used for loading the new configuration when reloading
+func (c *FailoverClient) SetConfigLoader(loader ConfigLoader) {
+	c.configLoader = loader
+}
+
+// Trigger a reload of the configuration.
+// Does nothing if a reload is already pending.
+func (c *FailoverClient) Reload() {
+	select {
+	case c.reload <- syscall.SIGHUP:
+	default:
+	}
+}
+
+// Load the configuration and apply it.
+// Keeps the current configuration when the new one can't be loaded.
+func (c *FailoverClient) reloadConfig() {
+	if c.configLoader == nil {
+		slog.Warn("Received request to reload config, but reloading is not supported")
+		return
+	}
+
+	slog.Info("Reloading configuration")
+	cfg, apply, err := c.configLoader()
+	if err == nil {
+		err = c.applyConfig(cfg)
+	}
+	if err != nil {
+		slog.Error("Failed to reload configuration, keeping the current one", "err", err)
+		return
+	}
+	if apply != nil {
+		apply()
+	}
+	slog.Info("Reloaded configuration")
+}
+
+// Apply the given configuration.
+// Nodes that are part of the old and new configuration keep their client and role cache.
+// Needs to be called while no jobs are running.
+// Does not change anything when returning an error.
+func (c *FailoverClient) applyConfig(cfg ValkeyConfig) error {
+	option, err := newClientOption(cfg.connection())
+	if err != nil {
+		return err
+	}
+
+	nodeOptions := make([]valkey.ClientOption, len(cfg.Nodes))
+	for i, nc := range cfg.Nodes {
+		nodeOptions[i], err = newClientOption(nc.connection(cfg))
+		if err != nil {
+			return fmt.Errorf("failed to create client options for node \"%s\": %w", nc.Address, err)
+		}
+	}
+
+	c.clientOption = option
+
+	existing := make(map[string]*node, len(c.nodes))
+	for _, n := range c.nodes {
+		existing[n.String()] = n
+	}
+
+	nodes := make([]*node, 0, len(cfg.Nodes))
+	for i, nc := range cfg.Nodes {
+		host, port := nc.HostAndPort(cfg.Port)
+		n := &node{
+			address:   host,
+			port:      port,
+			up:        true,
+			roleCache: &roleCache{},
+		}
+		if old, ok := existing[n.String()]; ok {
+			n = old
+			delete(existing, n.String())
+			if n.connection != nc.connection(cfg) {
+				// Reconnect on the next check with the new settings
+				n.close()
+			}
+		} else if c.cfg.Nodes != nil {
+			slog.Info("Adding node", slog.String("node", n.String()))
+		}
+		n.name = nc.Name
+		if n.name == "" {
+			n.name = n.String()
+		}
+		n.option = nodeOptions[i]
+		n.connection = nc.connection(cfg)
+		n.replication = cfg.Replication
+		if !n.replication.isSet() {
+			n.replication = n.connection.credentials()
+		}
+		n.roleCache.ttl = cfg.RoleCacheTTL
+		n.setDrained(nc.Drained)
+		n.linkDownTimeout = cfg.LinkDownTimeout
+		n.fencing = cfg.Fencing
+		n.dryRun = cfg.DryRun
+		if !n.dryRun {
+			n.setPending()
+		}
+		nodes = append(nodes, n)
+	}
+
+	for _, n := range existing {
+		slog.Info("Removing node", slog.String("node", n.String()))
+		n.close()
+		deleteNodeMetrics(n)
+		if n == c.drainedMaster {
+			c.setDrainedMaster(nil)
+		}
+		if n == c.masterNode {
+			c.masterNode = nil
+			c.currentMaster = ""
+			c.updateMasterMetrics()
+		}
+		if n == c.promotedMaster {
+			c.promotedMaster = nil
+		}
+	}
+
+	c.nodes = nodes
+	c.virtualAddress = cfg.VirtualAddress
+	c.port = cfg.Port
+
+	c.lock.Lock()
+	c.interval = cfg.CheckInterval
+	c.timeout = cfg.Timeout
+	c.pauseTimeout = cfg.PauseTimeout
+	c.lock.Unlock()
+
+	cfg.Nodes = slices.Clone(cfg.Nodes)
+	c.cfg = cfg
+	return nil
+}
@@ -0,0 +1,167 @@
+package failoverclient
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestConfig(nodes ...string) ValkeyConfig {
+	nodeConfigs := make([]NodeConfig, len(nodes))
+	for i, addr := range nodes {
+		nodeConfigs[i] = NodeConfig{Address: addr}
+	}
+	return ValkeyConfig{
+		VirtualAddress: "vaddress",
+		Port:           6379,
+		Nodes:          nodeConfigs,
+		CheckInterval:  DEFAULT_CHECK_INTERVAL,
+		Timeout:        DEFAULT_TIMEOUT,
+		RoleCacheTTL:   DEFAULT_ROLE_CACHE_TTL,
+	}
+}
+
+func newTestClient(t *testing.T, cfg ValkeyConfig) *FailoverClient {
+	c, err := NewFailoverClient(cfg)
+	require.NoError(t, err, "Should create client")
+	return c
+}
+
+func TestApplyConfig(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	c := newTestClient(t, newTestConfig("node1", "node2"))
+	require.Len(c.nodes, 2, "Should create all nodes")
+	node1, node2 := c.nodes[0], c.nodes[1]
+	node1.roleCache.Save(master, nil)
+	c.currentMaster = "runid2"
+	c.masterNode = node2
+
+	cfg := newTestConfig("node1", "node3:6380")
+	cfg.VirtualAddress = "vaddress2"
+	cfg.CheckInterval = 5 * time.Second
+	cfg.Timeout = 3 * time.Second
+	cfg.RoleCacheTTL = time.Hour
+	require.NoError(c.applyConfig(cfg), "Should apply config")
+
+	require.Len(c.nodes, 2, "Should have the new nodes")
+	assert.Same(node1, c.nodes[0], "Should keep unchanged nodes")
+	assert.True(node1.roleCache.IsMaster(), "Should keep the role cache of unchanged nodes")
+	assert.Equal(time.Hour, node1.roleCache.ttl, "Should update the role cache ttl")
+	assert.Equal("node3", c.nodes[1].address, "Should add new node")
+	assert.Equal(int64(6380), c.nodes[1].port, "Should add new node with port")
+	assert.True(c.nodes[1].up, "New nodes should start as up")
+
+	assert.Nil(c.masterNode, "Should reset the master when it was removed")
+	assert.Empty(c.currentMaster, "Should reset the current master when it was removed")
+
+	assert.Equal("vaddress2", c.virtualAddress, "Should update the virtual address")
+	assert.Equal(cfg.CheckInterval, c.interval, "Should update the interval")
+	assert.Equal(cfg.Timeout, c.timeout, "Should update the timeout")
+}
+
+func TestApplyConfigCredentialsChanged(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	_, n, err := newNodeWithMiniredis(t)
+	require.NoError(err, "Should create node with client")
+
+	cfg := newTestConfig(n.String())
+	c := newTestClient(t, cfg)
+	c.nodes[0].client = n.client
+
+	require.NoError(c.applyConfig(cfg), "Should apply config")
+	assert.NotNil(c.nodes[0].client, "Should keep the client when the credentials did not change")
+
+	cfg.Password = "newpassword"
+	require.NoError(c.applyConfig(cfg), "Should apply config")
+	assert.Nil(c.nodes[0].client, "Should close the client when the credentials changed")
+	assert.Equal("newpassword", c.clientOption.Password, "Should use the new credentials")
+}
+
+func TestApplyConfigReplicationCredentials(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	cfg := newTestConfig("node1", "node2")
+	cfg.Password = "globalpass"
+	cfg.Nodes[1].PasswordFile = "/run/secrets/node2-password"
+	c := newTestClient(t, cfg)
+
+	assert.Equal(Credentials{Password: "globalpass"}, c.nodes[0].replication, "Should default to the global credentials")
+	assert.Equal(Credentials{PasswordFile: "/run/secrets/node2-password"}, c.nodes[1].replication, "Should default to the node credentials")
+
+	cfg.Replication = Credentials{Username: "repl", Password: "replpass"}
+	require.NoError(c.applyConfig(cfg), "Should apply config")
+	for i, n := range c.nodes {
+		assert.Equalf(cfg.Replication, n.replication, "Node %d should use the dedicated replication credentials", i)
+	}
+}
+
+func TestReloadConfig(t *testing.T) {
+	t.Run("NoLoader", func(t *testing.T) {
+		c := newTestClient(t, newTestConfig("node1"))
+
+		assert.NotPanics(t, c.reloadConfig, "Should not panic without a loader")
+	})
+	t.Run("LoaderError", func(t *testing.T) {
+		assert := assert.New(t)
+
+		c := newTestClient(t, newTestConfig("node1"))
+		node1 := c.nodes[0]
+		c.SetConfigLoader(func() (ValkeyConfig, func(), error) {
+			return ValkeyConfig{}, nil, fmt.Errorf("invalid config")
+		})
+
+		c.reloadConfig()
+
+		assert.Equal([]*node{node1}, c.nodes, "Should keep the old nodes")
+		assert.Equal("vaddress", c.virtualAddress, "Should keep the old config")
+	})
+	t.Run("ApplyError", func(t *testing.T) {
+		assert := assert.New(t)
+
+		c := newTestClient(t, newTestConfig("node1"))
+		node1 := c.nodes[0]
+		applied := false
+		c.SetConfigLoader(func() (ValkeyConfig, func(), error) {
+			cfg := newTestConfig("node1", "node2")
+			cfg.TLS = TLSConfig{Enabled: true, CAFile: "/not/existing/ca.crt"}
+			return cfg, func() { applied = true }, nil
+		})
+
+		c.reloadConfig()
+
+		assert.Equal([]*node{node1}, c.nodes, "Should keep the old nodes")
+		assert.False(applied, "Should not apply the other settings when the client config can't be applied")
+	})
+	t.Run("Success", func(t *testing.T) {
+		assert := assert.New(t)
+
+		c := newTestClient(t, newTestConfig("node1"))
+		applied := false
+		c.SetConfigLoader(func() (ValkeyConfig, func(), error) {
+			return newTestConfig("node1", "node2"), func() { applied = true }, nil
+		})
+
+		c.reloadConfig()
+
+		assert.Len(c.nodes, 2, "Should apply the new config")
+		assert.True(applied, "Should apply the other settings")
+	})
+}
+
+func TestReload(t *testing.T) {
+	c := newTestClient(t, newTestConfig("node1"))
+
+	assert.NotPanics(t, func() {
+		c.Reload()
+		c.Reload()
+	}, "Should not block when a reload is already pending")
+	assert.Len(t, c.reload, 1, "Should have a pending reload")
+}
@@ -0,0 +1,129 @@
+package failoverclient
+
+import (
+	"log/slog"
+	"net"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// The split-brain condition, where the nodes disagree on who is the master
+type SplitBrain struct {
+	// The condition was seen in the last iteration
+	Active bool `json:"active"`
+	// The condition is kept until acknowledged
+	Latched bool      `json:"latched"`
+	Since   time.Time `json:"since,omitzero"`
+	// All nodes reporting to be master, when there is more than one
+	Masters []string `json:"masters,omitempty"`
+	// Replicas that are following a node other than the current master
+	Replicas []SplitBrainReplica `json:"replicas,omitempty"`
+}
+
+type SplitBrainReplica struct {
+	Node   string `json:"node"`
+	Master string `json:"master"`
+}
+
+// Check if both split-brains describe the same conflict
+func (s SplitBrain) sameConflict(other SplitBrain) bool {
+	return slices.Equal(s.Masters, other.Masters) && slices.Equal(s.Replicas, other.Replicas)
+}
+
+// Check if the roles of all reachable nodes agree on the master.
+// Uses the replication info retrieved by updateNodes, nodes changed since then are skipped until the next check.
+// Needs to be called while no jobs are running.
+func (c *FailoverClient) detectSplitBrain() {
+	var res SplitBrain
+	for _, n := range c.nodes {
+		if n.client == nil || n.drained || n.replicationInfo == nil {
+			continue
+		}
+		repl := *n.replicationInfo
+		switch repl.Role {
+		case master:
+			res.Masters = append(res.Masters, n.name)
+		case slave:
+			if c.masterNode != nil && !infoSlaveOfNode(repl, c.masterNode) {
+				res.Replicas = append(res.Replicas, SplitBrainReplica{
+					Node:   n.name,
+					Master: c.nodeName(repl.MasterHost, repl.MasterPort),
+				})
+			}
+		}
+	}
+	if len(res.Masters) < 2 {
+		res.Masters = nil
+	}
+	res.Active = res.Masters != nil || res.Replicas != nil
+
+	c.updateSplitBrain(res)
+}
+
+// Save the result of the split-brain detection, keeping latched conditions
+func (c *FailoverClient) updateSplitBrain(res SplitBrain) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	prev := c.splitBrain
+	switch {
+	case res.Active:
+		res.Latched = c.cfg.LatchSplitBrain
+		res.Since = prev.Since
+		if !prev.Active {
+			res.Since = time.Now()
+			splitBrainDetectionsTotal.Inc()
+		}
+		if !prev.Active || !res.sameConflict(prev) {
+			slog.Warn("Detected split-brain, nodes disagree on the master", slog.Any("masters", res.Masters), slog.Any("replicas", res.Replicas))
+		}
+		c.splitBrain = res
+	case prev.Active:
+		if prev.Latched {
+			slog.Info("Split-brain is resolved, keeping it latched until acknowledged")
+			c.splitBrain.Active = false
+		} else {
+			slog.Info("Split-brain is resolved")
+			c.splitBrain = SplitBrain{}
+		}
+	}
+
+	c.updateSplitBrainMetrics()
+}
+
+// Acknowledge a latched split-brain.
+// When the condition is still active, it is latched again on the next check.
+func (c *FailoverClient) AcknowledgeSplitBrain() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if !c.splitBrain.Latched {
+		return
+	}
+	slog.Info("Split-brain acknowledged")
+	if c.splitBrain.Active {
+		c.splitBrain.Latched = false
+	} else {
+		c.splitBrain = SplitBrain{}
+	}
+	c.updateSplitBrainMetrics()
+}
+
+// Return the current split-brain condition
+func (c *FailoverClient) SplitBrain() SplitBrain {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.splitBrain
+}
+
+// Return the name of the node with the given address, falls back to host:port for unknown nodes
+func (c *FailoverClient) nodeName(host string, port int64) string {
+	for _, n := range c.nodes {
+		if n.address == host && n.port == port {
+			return n.name
+		}
+	}
+	return net.JoinHostPort(host, strconv.FormatInt(port, 10))
+}
@@ -0,0 +1,130 @@
+package failoverclient
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectSplitBrain(t *testing.T) {
+	t.Run("NoSplitBrain", func(t *testing.T) {
+		assert := assert.New(t)
+
+		c, fakes := newFakeCluster(t, ValkeyConfig{}, "runid1", "runid2", "runid3")
+		c.masterNode = c.nodes[0]
+		fakes[1].setSlaveOf(c.nodes[0], linkStatusUp)
+		fakes[2].setSlaveOf(c.nodes[0], linkStatusDown)
+
+		c.updateNodes()
+		c.detectSplitBrain()
+
+		assert.Equal(SplitBrain{}, c.SplitBrain(), "Should not detect a split-brain")
+		assert.Equal(0.0, testutil.ToFloat64(splitBrainGauge), "Should not expose a split-brain")
+	})
+	t.Run("MultipleMasters", func(t *testing.T) {
+		assert := assert.New(t)
+
+		c, fakes := newFakeCluster(t, ValkeyConfig{}, "runid1", "runid2", "runid3")
+		c.masterNode = c.nodes[0]
+		fakes[1].setSlaveOf(c.nodes[0], linkStatusUp)
+		detections := testutil.ToFloat64(splitBrainDetectionsTotal)
+
+		c.updateNodes()
+		c.detectSplitBrain()
+
+		res := c.SplitBrain()
+		assert.True(res.Active, "Should detect the split-brain")
+		assert.False(res.Latched, "Should not latch when disabled")
+		assert.NotZero(res.Since, "Should remember when the split-brain started")
+		assert.Equal([]string{c.nodes[0].name, c.nodes[2].name}, res.Masters, "Should list all masters")
+		assert.Empty(res.Replicas, "Should have no wrong replicas")
+		assert.Equal(1.0, testutil.ToFloat64(splitBrainGauge), "Should expose the split-brain")
+		assert.Equal(detections+1, testutil.ToFloat64(splitBrainDetectionsTotal), "Should count the detection")
+
+		c.updateNodes()
+		c.detectSplitBrain()
+		assert.Equal(res.Since, c.SplitBrain().Since, "Should keep the start time while active")
+		assert.Equal(detections+1, testutil.ToFloat64(splitBrainDetectionsTotal), "Should count the detection only once")
+
+		fakes[2].setSlaveOf(c.nodes[0], linkStatusUp)
+		c.updateNodes()
+		c.detectSplitBrain()
+		assert.Equal(SplitBrain{}, c.SplitBrain(), "Should clear the split-brain when resolved")
+	})
+	t.Run("WrongReplicas", func(t *testing.T) {
+		assert := assert.New(t)
+
+		c, fakes := newFakeCluster(t, ValkeyConfig{}, "runid1", "runid2", "runid3")
+		c.masterNode = c.nodes[0]
+		fakes[1].setSlaveOf(&node{address: "unknown", port: 6379}, linkStatusUp)
+		fakes[2].setSlaveOf(c.nodes[1], linkStatusUp)
+		fakes[2].mr.Close()
+
+		c.updateNodes()
+		c.detectSplitBrain()
+
+		res := c.SplitBrain()
+		assert.True(res.Active, "Should detect the split-brain")
+		assert.Empty(res.Masters, "Should not list a single master")
+		assert.Equal([]SplitBrainReplica{{Node: c.nodes[1].name, Master: "unknown:6379"}}, res.Replicas, "Should list the replica following an unknown node and ignore unreachable nodes")
+	})
+	t.Run("ChangedSinceUpdate", func(t *testing.T) {
+		assert := assert.New(t)
+
+		c, fakes := newFakeCluster(t, ValkeyConfig{}, "runid1", "runid2", "runid3")
+		c.masterNode = c.nodes[0]
+		fakes[1].setSlaveOf(c.nodes[0], linkStatusUp)
+
+		c.updateNodes()
+		assert.NoError(c.nodes[2].slave(t.Context(), c.nodes[0]), "Should demote the second master")
+		c.detectSplitBrain()
+
+		assert.False(c.SplitBrain().Active, "Should skip the node changed after its role was retrieved")
+	})
+	t.Run("UnknownMaster", func(t *testing.T) {
+		assert := assert.New(t)
+
+		c, fakes := newFakeCluster(t, ValkeyConfig{}, "runid1", "runid2", "runid3")
+		fakes[1].setSlaveOf(&node{address: "unknown", port: 6379}, linkStatusUp)
+		fakes[2].setSlaveOf(&node{address: "unknown", port: 6379}, linkStatusUp)
+
+		c.updateNodes()
+		c.detectSplitBrain()
+
+		assert.False(c.SplitBrain().Active, "Should not check replicas without a known master")
+	})
+	t.Run("Latched", func(t *testing.T) {
+		assert := assert.New(t)
+
+		c, fakes := newFakeCluster(t, ValkeyConfig{LatchSplitBrain: true}, "runid1", "runid2", "runid3")
+		c.masterNode = c.nodes[0]
+		fakes[1].setSlaveOf(c.nodes[0], linkStatusUp)
+
+		c.updateNodes()
+		c.detectSplitBrain()
+		assert.True(c.SplitBrain().Latched, "Should latch the split-brain")
+
+		c.AcknowledgeSplitBrain()
+		assert.False(c.SplitBrain().Latched, "Should clear the latch on acknowledge")
+		c.updateNodes()
+		c.detectSplitBrain()
+		assert.True(c.SplitBrain().Latched, "Should latch again while still active")
+
+		fakes[2].setSlaveOf(c.nodes[0], linkStatusUp)
+		c.updateNodes()
+		c.detectSplitBrain()
+
+		res := c.SplitBrain()
+		assert.False(res.Active, "Should not be active anymore")
+		assert.True(res.Latched, "Should stay latched")
+		assert.Equal([]string{c.nodes[0].name, c.nodes[2].name}, res.Masters, "Should keep the conflicting nodes")
+		assert.Equal(1.0, testutil.ToFloat64(splitBrainGauge), "Should expose the latched split-brain")
+
+		c.recordIteration(true)
+		c.AcknowledgeSplitBrain()
+		assert.Equal(SplitBrain{}, c.SplitBrain(), "Should clear the split-brain on acknowledge")
+		assert.Equal(SplitBrain{}, c.Status().SplitBrain, "Status should show the acknowledgement immediately")
+		assert.Equal(0.0, testutil.ToFloat64(splitBrainGauge), "Should clear the metric")
+	})
+}
@@ -0,0 +1,177 @@
+package failoverclient
+
+import (
+	"slices"
+	"time"
+)
+
+// Snapshot of the current view the client has on the nodes
+type Status struct {
+	VirtualAddress string       `json:"virtualAddress"`
+	Master         *MasterState `json:"master,omitempty"`
+	// The last master confirmed to be promoted, differs from the master while the promotion is outstanding
+	PromotedMaster *MasterState `json:"promotedMaster,omitempty"`
+	Nodes          []NodeStatus `json:"nodes"`
+	Ready          bool         `json:"ready"`
+	DryRun         bool         `json:"dryRun,omitempty"`
+	// Set while the reconciliation is paused
+	Paused     *Pause     `json:"paused,omitempty"`
+	SplitBrain SplitBrain `json:"splitBrain"`
+	// Set while the virtual address is pointing to a drained node
+	DrainedMaster string `json:"drainedMaster,omitempty"`
+	// Set while a new master is waiting to be stable
+	PendingSwitchover *PendingSwitchover `json:"pendingSwitchover,omitempty"`
+	// Switchovers are refused, because there were too many within the window
+	SwitchoverLimited bool `json:"switchoverLimited,omitempty"`
+	// Set while the promotion of the node behind the virtual address is refused
+	PromotionBlocked *BlockedPromotion `json:"promotionBlocked,omitempty"`
+	LastSwitchover   *Switchover       `json:"lastSwitchover,omitempty"`
+	LastIteration    time.Time         `json:"lastIteration,omitzero"`
+}
+
+type MasterState struct {
+	RunID   string `json:"runID"`
+	Address string `json:"address"`
+	Port    int64  `json:"port"`
+}
+
+type NodeStatus struct {
+	Name          string    `json:"name"`
+	Address       string    `json:"address"`
+	Port          int64     `json:"port"`
+	RunID         string    `json:"runID,omitempty"`
+	Up            bool      `json:"up"`
+	Drained       bool      `json:"drained,omitempty"`
+	Role          string    `json:"role,omitempty"`
+	RoleExpire    time.Time `json:"roleExpire,omitzero"`
+	LinkStatus    string    `json:"linkStatus,omitempty"`
+	LinkDownSince time.Time `json:"linkDownSince,omitzero"`
+	// The last known replication offset of the node
+	ReplicationOffset     int64     `json:"replicationOffset"`
+	ReplicationOffsetTime time.Time `json:"replicationOffsetTime,omitzero"`
+	// The commands that were not send due to the dry-run mode
+	PendingCommands []string  `json:"pendingCommands,omitempty"`
+	LastError       string    `json:"lastError,omitempty"`
+	LastErrorTime   time.Time `json:"lastErrorTime,omitzero"`
+}
+
+// Save the time and result of the last completed iteration.
+// Takes a snapshot of the node states, so needs to be called while no jobs are running.
+func (c *FailoverClient) recordIteration(ready bool) {
+	status := Status{
+		VirtualAddress: c.virtualAddress,
+		Nodes:          make([]NodeStatus, len(c.nodes)),
+		Ready:          ready,
+		DryRun:         c.cfg.DryRun,
+		LastIteration:  time.Now(),
+
+		SwitchoverLimited: c.switchoverLimited,
+	}
+	if c.pendingSwitchover != nil {
+		pending := *c.pendingSwitchover
+		status.PendingSwitchover = &pending
+	}
+	if c.drainedMaster != nil {
+		status.DrainedMaster = c.drainedMaster.name
+	}
+	if c.blockedPromotion != nil {
+		blocked := *c.blockedPromotion
+		status.PromotionBlocked = &blocked
+	}
+	if c.lastSwitchover != nil {
+		switchover := *c.lastSwitchover
+		status.LastSwitchover = &switchover
+	}
+	if c.masterNode != nil {
+		status.Master = &MasterState{
+			RunID:   c.currentMaster,
+			Address: c.masterNode.address,
+			Port:    c.masterNode.port,
+		}
+	}
+	if c.promotedMaster != nil {
+		status.PromotedMaster = &MasterState{
+			RunID:   c.promotedMaster.runID,
+			Address: c.promotedMaster.address,
+			Port:    c.promotedMaster.port,
+		}
+	}
+	for i, n := range c.nodes {
+		status.Nodes[i] = n.status()
+	}
+
+	c.lock.Lock()
+	previous := c.status.PromotedMaster
+	c.status = status
+	hooks := c.switchoverHooks
+	c.lock.Unlock()
+
+	if previous != nil && status.PromotedMaster != nil && *previous != *status.PromotedMaster {
+		for _, fn := range hooks {
+			fn(*previous, *status.PromotedMaster)
+		}
+	}
+}
+
+// Register a function that is called with the old and new master after an iteration promoted a new master.
+// It is not called when the first master is promoted. The function is called from the failover loop, so it should not block.
+func (c *FailoverClient) OnSwitchover(fn func(from, to MasterState)) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.switchoverHooks = append(c.switchoverHooks, fn)
+}
+
+// Return the status from the last completed iteration.
+// The split-brain condition and pause are always the current ones, so changes are visible immediately.
+func (c *FailoverClient) Status() Status {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	res := c.status
+	res.SplitBrain = c.splitBrain
+	res.Paused = c.currentPause()
+	return res
+}
+
+// Check if the failover loop has completed an iteration recently.
+// An iteration can take up to one interval plus the timeouts, so both are taken into account.
+func (c *FailoverClient) Healthy() bool {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return time.Since(c.status.LastIteration) < maxMissedIntervals*(c.interval+c.timeout)
+}
+
+// Check if the virtual address is reachable and the master has been resolved to a known node
+func (c *FailoverClient) Ready() bool {
+	return c.Status().Ready
+}
+
+// Return the current state of the node
+func (n *node) status() NodeStatus {
+	res := NodeStatus{
+		Name:          n.name,
+		Address:       n.address,
+		Port:          n.port,
+		RunID:         n.runID,
+		Up:            n.up,
+		Drained:       n.drained,
+		LinkStatus:    n.linkStatus,
+		LinkDownSince: n.linkDownSince,
+
+		ReplicationOffset:     n.offset,
+		ReplicationOffsetTime: n.offsetTime,
+
+		PendingCommands: slices.Clone(n.pending),
+	}
+	if n.roleCache != nil {
+		res.Role = n.roleCache.role
+		res.RoleExpire = n.roleCache.expire
+	}
+	if n.lastError != nil {
+		res.LastError = n.lastError.Error()
+		res.LastErrorTime = n.lastErrorTime
+	}
+	return res
+}
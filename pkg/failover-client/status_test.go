@@ -0,0 +1,102 @@
+package failoverclient
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientHealthAndReadiness(t *testing.T) {
+	assert := assert.New(t)
+
+	c := &FailoverClient{interval: DEFAULT_CHECK_INTERVAL, timeout: DEFAULT_TIMEOUT}
+	assert.False(c.Healthy(), "Should not be healthy before the first iteration")
+	assert.False(c.Ready(), "Should not be ready before the first iteration")
+
+	c.recordIteration(false)
+	assert.True(c.Healthy(), "Should be healthy after an iteration")
+	assert.False(c.Ready(), "Should not be ready after a failed iteration")
+
+	c.recordIteration(true)
+	assert.True(c.Ready(), "Should be ready after a successful iteration")
+
+	c.status.LastIteration = time.Now().Add(-maxMissedIntervals * (DEFAULT_CHECK_INTERVAL + DEFAULT_TIMEOUT))
+	assert.False(c.Healthy(), "Should not be healthy when no iteration completed recently")
+}
+
+func TestClientStatus(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	masterNode := &node{address: "node1", port: 6379, runID: "runid1", up: true, roleCache: &roleCache{ttl: DEFAULT_ROLE_CACHE_TTL}}
+	masterNode.roleCache.Save(master, nil)
+	slaveNode := &node{address: "node2", port: 6380, up: false, roleCache: &roleCache{}, linkStatus: linkStatusDown, linkDownSince: time.Now()}
+	slaveNode.setError(fmt.Errorf("connection refused"))
+
+	c := &FailoverClient{
+		virtualAddress: "vaddress",
+		nodes:          []*node{masterNode, slaveNode},
+		currentMaster:  "runid1",
+		masterNode:     masterNode,
+		promotedMaster: masterNode,
+	}
+
+	c.recordIteration(true)
+	status := c.Status()
+
+	assert.Equal("vaddress", status.VirtualAddress, "Should contain the virtual address")
+	assert.True(status.Ready, "Should be ready")
+	require.NotNil(status.Master, "Should contain the master")
+	assert.Equal(MasterState{RunID: "runid1", Address: "node1", Port: 6379}, *status.Master, "Should contain the current master")
+	require.NotNil(status.PromotedMaster, "Should contain the promoted master")
+	assert.Equal(*status.Master, *status.PromotedMaster, "Should contain the promoted master")
+
+	require.Len(status.Nodes, 2, "Should contain all nodes")
+	assert.Equal("runid1", status.Nodes[0].RunID, "Should contain the run_id")
+	assert.True(status.Nodes[0].Up, "Node 1 should be up")
+	assert.Equal(master, status.Nodes[0].Role, "Should contain the cached role")
+	assert.Equal(masterNode.roleCache.expire, status.Nodes[0].RoleExpire, "Should contain the cache expiry")
+	assert.Empty(status.Nodes[0].LastError, "Node 1 should have no error")
+
+	assert.False(status.Nodes[1].Up, "Node 2 should be down")
+	assert.Empty(status.Nodes[1].Role, "Node 2 should have no role")
+	assert.Equal("connection refused", status.Nodes[1].LastError, "Should contain the last error")
+	assert.False(status.Nodes[1].LastErrorTime.IsZero(), "Should contain the time of the last error")
+	assert.Equal(linkStatusDown, status.Nodes[1].LinkStatus, "Should contain the link status")
+	assert.Equal(slaveNode.linkDownSince, status.Nodes[1].LinkDownSince, "Should contain since when the link is down")
+}
+
+func TestOnSwitchover(t *testing.T) {
+	assert := assert.New(t)
+
+	node1 := &node{address: "node1", port: 6379, runID: "runid1"}
+	node2 := &node{address: "node2", port: 6379, runID: "runid2"}
+	c := &FailoverClient{nodes: []*node{node1, node2}}
+
+	var switchovers [][2]MasterState
+	c.OnSwitchover(func(from, to MasterState) {
+		switchovers = append(switchovers, [2]MasterState{from, to})
+	})
+
+	c.currentMaster = "runid1"
+	c.masterNode = node1
+	c.promotedMaster = node1
+	c.recordIteration(true)
+	c.recordIteration(true)
+	assert.Empty(switchovers, "Should not call the hook for the first master or without a change")
+
+	c.currentMaster = "runid2"
+	c.masterNode = node2
+	c.recordIteration(true)
+	assert.Empty(switchovers, "Should not call the hook before the new master is promoted")
+
+	c.promotedMaster = node2
+	c.recordIteration(true)
+	assert.Equal([][2]MasterState{{
+		{RunID: "runid1", Address: "node1", Port: 6379},
+		{RunID: "runid2", Address: "node2", Port: 6379},
+	}}, switchovers, "Should call the hook with the old and new master")
+}
@@ -0,0 +1,173 @@
+package failoverclient
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"go.yaml.in/yaml/v3"
+)
+
+var tlsVersions = map[string]uint16{
+	"":    tls.VersionTLS12,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+type TLSConfig struct {
+	Enabled            bool   `yaml:"enabled,omitempty"`
+	CAFile             string `yaml:"caFile,omitempty"`
+	CertFile           string `yaml:"certFile,omitempty"`
+	KeyFile            string `yaml:"keyFile,omitempty"`
+	ServerName         string `yaml:"serverName,omitempty"`
+	InsecureSkipVerify bool   `yaml:"insecureSkipVerify,omitempty"`
+	MinVersion         string `yaml:"minVersion,omitempty"`
+}
+
+// Support the old format, where tls was only a boolean
+func (c *TLSConfig) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		return value.Decode(&c.Enabled)
+	}
+
+	type plain TLSConfig
+	return value.Decode((*plain)(c))
+}
+
+// Ensure that the given config is valid
+func (c TLSConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if (c.CertFile == "") != (c.KeyFile == "") {
+		return fmt.Errorf("need to set both tls certFile and keyFile")
+	}
+	if _, ok := tlsVersions[c.MinVersion]; !ok {
+		return fmt.Errorf("unsupported tls minVersion \"%s\", needs to be one of 1.2, 1.3", c.MinVersion)
+	}
+	return nil
+}
+
+// Creates the tls config for new connections.
+// Checks the certificate files for changes and reloads them, so rotated certificates are used without restarting.
+type tlsLoader struct {
+	cfg TLSConfig
+
+	lock     sync.Mutex
+	modTimes map[string]time.Time
+	current  *tls.Config
+}
+
+// Create a new loader and load the certificates
+func newTLSLoader(cfg TLSConfig) (*tlsLoader, error) {
+	l := &tlsLoader{
+		cfg: cfg,
+	}
+	modTimes, err := l.readModTimes()
+	if err != nil {
+		return nil, err
+	}
+	l.current, err = l.load()
+	if err != nil {
+		return nil, err
+	}
+	l.modTimes = modTimes
+	return l, nil
+}
+
+// Return the current tls config, reloading it when the files changed.
+// Keeps the old config when the files can't be loaded.
+func (l *tlsLoader) config() *tls.Config {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	modTimes, err := l.readModTimes()
+	if err != nil {
+		slog.Error("Failed to check tls files for changes", "err", err)
+		return l.current
+	}
+	changed := false
+	for file, modTime := range modTimes {
+		if !l.modTimes[file].Equal(modTime) {
+			changed = true
+		}
+	}
+	if !changed {
+		return l.current
+	}
+
+	current, err := l.load()
+	if err != nil {
+		slog.Error("Failed to reload tls files, keeping the old ones", "err", err)
+		return l.current
+	}
+	slog.Info("Reloaded tls files")
+	l.current = current
+	l.modTimes = modTimes
+	return l.current
+}
+
+// Dial a new tls connection with the current config.
+// Used as dial function for valkey clients.
+func (l *tlsLoader) dial(ctx context.Context, dst string, dialer *net.Dialer, _ *tls.Config) (net.Conn, error) {
+	d := tls.Dialer{
+		NetDialer: dialer,
+		Config:    l.config(),
+	}
+	return d.DialContext(ctx, "tcp", dst)
+}
+
+// Create a new tls config from the files
+func (l *tlsLoader) load() (*tls.Config, error) {
+	// #nosec G402: Skipping verification needs to be explicitly enabled by the user.
+	tlsConfig := &tls.Config{
+		MinVersion:         tlsVersions[l.cfg.MinVersion],
+		ServerName:         l.cfg.ServerName,
+		InsecureSkipVerify: l.cfg.InsecureSkipVerify,
+	}
+
+	if l.cfg.CAFile != "" {
+		// #nosec G304: Local users can decide on the ca file path freely.
+		ca, err := os.ReadFile(l.cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read tls caFile: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("no certificates found in tls caFile \"%s\"", l.cfg.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if l.cfg.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(l.cfg.CertFile, l.cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load tls client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// Return the modification time of all configured files
+func (l *tlsLoader) readModTimes() (map[string]time.Time, error) {
+	res := make(map[string]time.Time, 3)
+	for _, file := range []string{l.cfg.CAFile, l.cfg.CertFile, l.cfg.KeyFile} {
+		if file == "" {
+			continue
+		}
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, err
+		}
+		res[file] = info.ModTime()
+	}
+	return res, nil
+}
@@ -0,0 +1,219 @@
+package failoverclient
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.yaml.in/yaml/v3"
+)
+
+func TestTLSConfigUnmarshalYAML(t *testing.T) {
+	tMatrix := map[string]struct {
+		input  string
+		result TLSConfig
+	}{
+		"BoolTrue": {
+			input:  "tls: true",
+			result: TLSConfig{Enabled: true},
+		},
+		"BoolFalse": {
+			input:  "tls: false",
+			result: TLSConfig{},
+		},
+		"Block": {
+			input:  "tls:\n  enabled: true\n  caFile: /ca.crt\n  serverName: valkey\n  minVersion: \"1.3\"",
+			result: TLSConfig{Enabled: true, CAFile: "/ca.crt", ServerName: "valkey", MinVersion: "1.3"},
+		},
+	}
+
+	for name, tCase := range tMatrix {
+		t.Run(name, func(t *testing.T) {
+			var res struct {
+				TLS TLSConfig `yaml:"tls"`
+			}
+			err := yaml.Unmarshal([]byte(tCase.input), &res)
+
+			require.NoError(t, err, "Should parse config")
+			assert.Equal(t, tCase.result, res.TLS, "Should have the expected result")
+		})
+	}
+}
+
+func TestTLSConfigValidate(t *testing.T) {
+	tMatrix := map[string]struct {
+		cfg   TLSConfig
+		valid bool
+	}{
+		"Disabled": {
+			cfg:   TLSConfig{CertFile: "/tls.crt", MinVersion: "invalid"},
+			valid: true,
+		},
+		"Full": {
+			cfg:   TLSConfig{Enabled: true, CAFile: "/ca.crt", CertFile: "/tls.crt", KeyFile: "/tls.key", MinVersion: "1.2"},
+			valid: true,
+		},
+		"MissingKeyFile": {
+			cfg:   TLSConfig{Enabled: true, CertFile: "/tls.crt"},
+			valid: false,
+		},
+		"MissingCertFile": {
+			cfg:   TLSConfig{Enabled: true, KeyFile: "/tls.key"},
+			valid: false,
+		},
+		"UnsupportedMinVersion": {
+			cfg:   TLSConfig{Enabled: true, MinVersion: "1.0"},
+			valid: false,
+		},
+	}
+
+	for name, tCase := range tMatrix {
+		t.Run(name, func(t *testing.T) {
+			if tCase.valid {
+				assert.NoError(t, tCase.cfg.Validate())
+			} else {
+				assert.Error(t, tCase.cfg.Validate())
+			}
+		})
+	}
+}
+
+func TestNewTLSLoader(t *testing.T) {
+	t.Run("MissingCAFile", func(t *testing.T) {
+		_, err := newTLSLoader(TLSConfig{Enabled: true, CAFile: "not-a-file"})
+		assert.Error(t, err, "Should fail when the ca file does not exist")
+	})
+	t.Run("InvalidCAFile", func(t *testing.T) {
+		caFile := filepath.Join(t.TempDir(), "ca.crt")
+		require.NoError(t, os.WriteFile(caFile, []byte("not a certificate"), 0600))
+
+		_, err := newTLSLoader(TLSConfig{Enabled: true, CAFile: caFile})
+		assert.Error(t, err, "Should fail when the ca file contains no certificates")
+	})
+	t.Run("Success", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		dir := t.TempDir()
+		caFile, certFile, keyFile := writeTestCertificates(t, dir, "first")
+
+		l, err := newTLSLoader(TLSConfig{
+			Enabled:            true,
+			CAFile:             caFile,
+			CertFile:           certFile,
+			KeyFile:            keyFile,
+			ServerName:         "valkey",
+			InsecureSkipVerify: true,
+			MinVersion:         "1.3",
+		})
+		require.NoError(err, "Should load certificates")
+
+		cfg := l.config()
+		assert.Equal(uint16(tls.VersionTLS13), cfg.MinVersion, "Should set min version")
+		assert.Equal("valkey", cfg.ServerName, "Should set server name")
+		assert.True(cfg.InsecureSkipVerify, "Should skip verification")
+		assert.NotNil(cfg.RootCAs, "Should set ca")
+		assert.Len(cfg.Certificates, 1, "Should set client certificate")
+	})
+}
+
+func TestTLSLoaderReload(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	dir := t.TempDir()
+	caFile, certFile, keyFile := writeTestCertificates(t, dir, "first")
+
+	l, err := newTLSLoader(TLSConfig{Enabled: true, CAFile: caFile, CertFile: certFile, KeyFile: keyFile})
+	require.NoError(err, "Should load certificates")
+
+	first := l.config()
+	assert.Same(first, l.config(), "Should not reload unchanged files")
+
+	writeTestCertificates(t, dir, "second")
+	for _, file := range []string{caFile, certFile, keyFile} {
+		future := time.Now().Add(time.Minute)
+		require.NoError(os.Chtimes(file, future, future))
+	}
+	second := l.config()
+	assert.NotSame(first, second, "Should reload changed files")
+	assert.NotEqual(first.Certificates[0].Certificate, second.Certificates[0].Certificate, "Should use the new certificate")
+
+	require.NoError(os.WriteFile(certFile, []byte("broken"), 0600))
+	assert.Same(second, l.config(), "Should keep the old config when the new files are invalid")
+}
+
+func TestTLSLoaderDial(t *testing.T) {
+	require := require.New(t)
+
+	dir := t.TempDir()
+	caFile, certFile, keyFile := writeTestCertificates(t, dir, "dial")
+
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	require.NoError(err, "Should load server certificate")
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12})
+	require.NoError(err, "Should start tls listener")
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		_ = conn.(*tls.Conn).Handshake()
+		conn.Close()
+	}()
+
+	l, err := newTLSLoader(TLSConfig{Enabled: true, CAFile: caFile, ServerName: "valkey"})
+	require.NoError(err, "Should load ca")
+
+	conn, err := l.dial(t.Context(), ln.Addr().String(), &net.Dialer{}, nil)
+	require.NoError(err, "Should verify the server with the ca")
+	conn.Close()
+}
+
+// Write a self-signed certificate for the server name "valkey", that is used as ca and certificate.
+// Returns the paths of the ca, certificate and key file.
+func writeTestCertificates(t *testing.T, dir, name string) (string, string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err, "Should generate key")
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		DNSNames:              []string{"valkey"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err, "Should create certificate")
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err, "Should marshal key")
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+
+	caFile := filepath.Join(dir, "ca.crt")
+	certFile := filepath.Join(dir, "tls.crt")
+	keyFile := filepath.Join(dir, "tls.key")
+	require.NoError(t, os.WriteFile(caFile, certPEM, 0600))
+	require.NoError(t, os.WriteFile(certFile, certPEM, 0600))
+	require.NoError(t, os.WriteFile(keyFile, keyPEM, 0600))
+
+	return caFile, certFile, keyFile
+}
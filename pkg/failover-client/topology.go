@@ -0,0 +1,106 @@
+package failoverclient
+
+import (
+	"context"
+	"time"
+
+	valkeyinfo "github.com/heathcliff26/valkey-keepalived/pkg/valkey-info"
+)
+
+// The replication state of a node as reported by the node itself
+type NodeTopology struct {
+	Name                 string `json:"name"`
+	Address              string `json:"address"`
+	Port                 int64  `json:"port"`
+	Reachable            bool   `json:"reachable"`
+	RunID                string `json:"runID,omitempty"`
+	Role                 string `json:"role,omitempty"`
+	MasterHost           string `json:"masterHost,omitempty"`
+	MasterPort           int64  `json:"masterPort,omitempty"`
+	MasterLinkStatus     string `json:"masterLinkStatus,omitempty"`
+	ReplicationOffset    int64  `json:"replicationOffset"`
+	BehindVirtualAddress bool   `json:"behindVirtualAddress"`
+	Error                string `json:"error,omitempty"`
+}
+
+// The replication state of all nodes
+type Topology struct {
+	VirtualAddress      string         `json:"virtualAddress"`
+	VirtualAddressRunID string         `json:"virtualAddressRunID,omitempty"`
+	VirtualAddressError string         `json:"virtualAddressError,omitempty"`
+	Nodes               []NodeTopology `json:"nodes"`
+}
+
+// Connect once to all nodes and the virtual address and retrieve their current replication state.
+// Does not change anything on the nodes.
+func QueryTopology(cfg ValkeyConfig, timeout time.Duration) (Topology, error) {
+	c, err := NewFailoverClient(cfg)
+	if err != nil {
+		return Topology{}, err
+	}
+	defer c.Close()
+
+	res := Topology{
+		VirtualAddress: c.virtualAddress,
+		Nodes:          make([]NodeTopology, len(c.nodes)),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	client, err := newValkeyClient(c.virtualAddress, c.port, c.clientOption)
+	if err == nil {
+		var info string
+		info, err = client.Do(ctx, client.B().Info().Section(valkeyinfo.SectionServer).Build()).ToString()
+		client.Close()
+		res.VirtualAddressRunID = valkeyinfo.Parse(info).Server.RunID
+	}
+	if err != nil {
+		res.VirtualAddressError = err.Error()
+	}
+
+	index := make(map[*node]int, len(c.nodes))
+	for i, n := range c.nodes {
+		index[n] = i
+	}
+
+	c.parallelJob(timeout, func(ctx context.Context, n *node) {
+		res.Nodes[index[n]] = n.topology(ctx, res.VirtualAddressRunID)
+	})
+
+	return res, nil
+}
+
+// Connect to the node and read the replication state
+func (n *node) topology(ctx context.Context, vaRunID string) NodeTopology {
+	res := NodeTopology{
+		Name:    n.name,
+		Address: n.address,
+		Port:    n.port,
+	}
+
+	err := n.connect(ctx)
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	res.Reachable = true
+	res.RunID = n.runID
+	res.BehindVirtualAddress = vaRunID != "" && n.runID == vaRunID
+
+	repl, err := n.getReplicationInfo(ctx)
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+
+	res.Role = repl.Role
+	if res.Role == slave {
+		res.MasterHost = repl.MasterHost
+		res.MasterPort = repl.MasterPort
+		res.MasterLinkStatus = repl.MasterLinkStatus
+	}
+	res.ReplicationOffset = repl.Offset()
+
+	return res
+}
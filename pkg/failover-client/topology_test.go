@@ -0,0 +1,35 @@
+package failoverclient
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryTopology(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	mr := miniredis.RunT(t)
+
+	cfg := ValkeyConfig{
+		VirtualAddress: mr.Host(),
+		Port:           int64(mr.Server().Addr().Port),
+		Nodes:          []NodeConfig{{Address: mr.Addr()}},
+	}
+
+	res, err := QueryTopology(cfg, time.Second)
+	require.NoError(err, "Should query topology")
+
+	assert.Equal(mr.Host(), res.VirtualAddress, "Should contain the virtual address")
+	assert.Equal("section (server) is not supported", res.VirtualAddressError, "Should return miniredis error for the virtual address")
+
+	require.Len(res.Nodes, 1, "Should contain all nodes")
+	assert.Equal(mr.Host(), res.Nodes[0].Address, "Should contain the node address")
+	assert.False(res.Nodes[0].Reachable, "Should not mark node as reachable when info fails")
+	assert.Equal("section (server) is not supported", res.Nodes[0].Error, "Should return miniredis error for the node")
+	assert.False(res.Nodes[0].BehindVirtualAddress, "Should not be behind the virtual address")
+}
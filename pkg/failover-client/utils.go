@@ -5,8 +5,8 @@ import (
 	"log/slog"
 	"net"
 	"strconv"
-	"strings"
 
+	valkeyinfo "github.com/heathcliff26/valkey-keepalived/pkg/valkey-info"
 	"github.com/valkey-io/valkey-go"
 )
 
@@ -16,21 +16,14 @@ func newValkeyClient(addr string, port int64, option valkey.ClientOption) (valke
 }
 
 // Takes a given info result from valkey and extracts the wanted value
+//
+// Deprecated: Use valkeyinfo.Parse and the typed sections or Info.Get instead.
 func ParseValueFromInfo(info string, key string) string {
-	fields := strings.Split(info, "\r\n")
-
-	for _, field := range fields {
-		keyval := strings.SplitN(field, ":", 2)
-		if len(keyval) != 2 {
-			continue
-		}
-		if keyval[0] == key {
-			return keyval[1]
-		}
+	value, ok := valkeyinfo.Parse(info).Get(key)
+	if !ok {
+		slog.Error("Could not find the requested key in info", "info", info, "key", key)
 	}
-
-	slog.Error("Could not find the requested key in info", "info", info, "key", key)
-	return ""
+	return value
 }
 
 // Extract the host and port from an address string.
@@ -47,14 +40,10 @@ func extractPortFromAddress(address string, defaultPort int64) (string, int64) {
 	return host, int64(port)
 }
 
-// Check if the given info string shows that the node is slave of the given node
-func infoSlaveOfNode(info string, n *node) bool {
+// Check if the given replication info shows that the node is slave of the given node
+func infoSlaveOfNode(repl valkeyinfo.Replication, n *node) bool {
 	if n == nil {
 		return false
 	}
-	currentRole := ParseValueFromInfo(info, role)
-	addr := ParseValueFromInfo(info, masterHost)
-	portStr := ParseValueFromInfo(info, masterPort)
-
-	return currentRole == slave && addr == n.address && portStr == strconv.FormatInt(n.port, 10)
+	return repl.IsSlaveOf(n.address, n.port)
 }
@@ -3,6 +3,7 @@ package failoverclient
 import (
 	"testing"
 
+	valkeyinfo "github.com/heathcliff26/valkey-keepalived/pkg/valkey-info"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -11,9 +12,9 @@ const testInfo = "txt:# Replication\r\nrole:master\r\nconnected_slaves:2\r\nslav
 func TestParseValueFromInfo(t *testing.T) {
 	assert := assert.New(t)
 
-	assert.Equal(master, ParseValueFromInfo(testInfo, role))
+	assert.Equal(master, ParseValueFromInfo(testInfo, "role"))
 
-	assert.Equal(master, ParseValueFromInfo("\r\ntest\r\nrole:master\r\nconnected_slaves:2", role), "Should not panic when split does not work correctly")
+	assert.Equal(master, ParseValueFromInfo("\r\ntest\r\nrole:master\r\nconnected_slaves:2", "role"), "Should not panic when split does not work correctly")
 
 	assert.Equal("", ParseValueFromInfo("", "not-a-key"), "Should return an empty string if no value is found")
 }
@@ -72,6 +73,11 @@ func TestInfoSlaveOfNode(t *testing.T) {
 	assert := assert.New(t)
 
 	assert.NotPanics(func() {
-		assert.False(infoSlaveOfNode(testInfo, nil), "Should return false when node is nil")
+		assert.False(infoSlaveOfNode(valkeyinfo.Replication{Role: slave}, nil), "Should return false when node is nil")
 	}, "Should not panic when node is nil")
+
+	repl := valkeyinfo.Replication{Role: slave, MasterHost: "node1", MasterPort: 6379}
+	assert.True(infoSlaveOfNode(repl, &node{address: "node1", port: 6379}), "Should be slave of node1")
+	assert.False(infoSlaveOfNode(repl, &node{address: "node1", port: 6380}), "Should not be slave of node with other port")
+	assert.False(infoSlaveOfNode(valkeyinfo.Replication{Role: master}, &node{address: "node1", port: 6379}), "Master should not be slave")
 }
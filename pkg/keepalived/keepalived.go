@@ -0,0 +1,183 @@
+package keepalived
+
+import (
+	_ "embed"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+
+	"github.com/heathcliff26/valkey-keepalived/pkg/config"
+)
+
+const (
+	DEFAULT_INSTANCE          = "VI_VALKEY"
+	DEFAULT_INTERFACE         = "eth0"
+	DEFAULT_VIRTUAL_ROUTER_ID = 51
+	DEFAULT_BINARY            = "/usr/bin/valkey-keepalived"
+
+	// Priority of the first node, every following node gets priorityStep less
+	maxPriority  = 200
+	priorityStep = 10
+	// Limits of priority and weight accepted by keepalived
+	minPriority = 1
+	maxWeight   = 253
+
+	stateMaster = "MASTER"
+	stateBackup = "BACKUP"
+)
+
+//go:embed keepalived.conf.tmpl
+var defaultTemplate string
+
+// Settings for rendering the keepalived config of a single node
+type Options struct {
+	// Name or address of the node the config is rendered for
+	Node string
+	// Name of the vrrp_instance
+	Instance string
+	// Interface the virtual address is assigned to
+	Interface       string
+	VirtualRouterID int64
+	// Path to the valkey-keepalived binary used in the scripts
+	Binary string
+	// Path to the config file used in the scripts
+	ConfigPath string
+	// Pass --env to the scripts
+	Env bool
+	// Custom template, uses the default template when empty
+	Template string
+}
+
+// The values available in the template
+type Data struct {
+	// The node the config is rendered for
+	Node Node
+	// All nodes in the order of the config
+	Nodes           []Node
+	Instance        string
+	Interface       string
+	VirtualRouterID int64
+	VirtualAddress  string
+	// Initial state of the vrrp_instance, MASTER for the first node, BACKUP for all others
+	State string
+	// Interval and timeout of the health check in seconds
+	Interval int64
+	Timeout  int64
+	// Subtracted from the priority when the health check fails, large enough to drop the node below all others
+	Weight        int64
+	CheckCommand  string
+	NotifyCommand string
+}
+
+type Node struct {
+	Name     string
+	Host     string
+	Port     int64
+	Priority int64
+}
+
+// Render the keepalived config for the given node
+func Generate(w io.Writer, cfg config.Config, opts Options) error {
+	data, err := newData(cfg, opts)
+	if err != nil {
+		return err
+	}
+
+	text := opts.Template
+	if text == "" {
+		text = defaultTemplate
+	}
+	tmpl, err := template.New("keepalived").Parse(text)
+	if err != nil {
+		return fmt.Errorf("failed to parse template: %w", err)
+	}
+
+	err = tmpl.Execute(w, data)
+	if err != nil {
+		return fmt.Errorf("failed to render template: %w", err)
+	}
+	return nil
+}
+
+// Collect the values for the template
+func newData(cfg config.Config, opts Options) (Data, error) {
+	if net.ParseIP(cfg.Valkey.VirtualAddress) == nil {
+		return Data{}, fmt.Errorf("virtual address \"%s\" needs to be an ip to be used by keepalived", cfg.Valkey.VirtualAddress)
+	}
+	if opts.VirtualRouterID < 1 || opts.VirtualRouterID > 255 {
+		return Data{}, fmt.Errorf("invalid virtual router id, needs to be between 1-255")
+	}
+	index := cfg.Valkey.NodeIndex(opts.Node)
+	if index < 0 {
+		return Data{}, fmt.Errorf("node \"%s\" is not part of the config", opts.Node)
+	}
+
+	nodes := make([]Node, 0, len(cfg.Valkey.Nodes))
+	for i, n := range cfg.Valkey.Nodes {
+		host, port := n.HostAndPort(cfg.Valkey.Port)
+		nodes = append(nodes, Node{
+			Name:     n.DisplayName(cfg.Valkey.Port),
+			Host:     host,
+			Port:     port,
+			Priority: max(maxPriority-int64(i)*priorityStep, minPriority),
+		})
+	}
+
+	state := stateBackup
+	if index == 0 {
+		state = stateMaster
+	}
+
+	check := []string{opts.Binary, "check", "-c", opts.ConfigPath, "--node", nodes[index].Name, "--timeout", cfg.Valkey.Timeout.String()}
+	if opts.Env {
+		check = append(check, "--env")
+	}
+	checkCommand, err := scriptCommand(check...)
+	if err != nil {
+		return Data{}, err
+	}
+	var notifyCommand string
+	if cfg.Notify.Enabled {
+		notifyCommand, err = scriptCommand(opts.Binary, "notify", "--socket", cfg.Notify.Socket)
+		if err != nil {
+			return Data{}, err
+		}
+	}
+
+	return Data{
+		Node:            nodes[index],
+		Nodes:           nodes,
+		Instance:        opts.Instance,
+		Interface:       opts.Interface,
+		VirtualRouterID: opts.VirtualRouterID,
+		VirtualAddress:  cfg.Valkey.VirtualAddress,
+		State:           state,
+		Interval:        seconds(cfg.Valkey.CheckInterval),
+		// Leave the check time to report its own timeout
+		Timeout:       seconds(cfg.Valkey.Timeout) + 1,
+		Weight:        -min(int64(len(nodes))*priorityStep, maxWeight),
+		CheckCommand:  checkCommand,
+		NotifyCommand: notifyCommand,
+	}, nil
+}
+
+// Join the arguments to the command line of a script.
+// keepalived splits the command line at whitespace, so arguments containing whitespace or quotes are rejected.
+func scriptCommand(args ...string) (string, error) {
+	for _, arg := range args {
+		if arg == "" || strings.ContainsFunc(arg, func(r rune) bool { return unicode.IsSpace(r) || r == '"' || r == '\'' || r == '\\' }) {
+			return "", fmt.Errorf("script argument \"%s\" can't be empty or contain whitespace or quotes", arg)
+		}
+	}
+	return strings.Join(args, " "), nil
+}
+
+// Round the duration up to full seconds, keepalived does not accept less than 1 second
+func seconds(d time.Duration) int64 {
+	return max(int64(math.Ceil(d.Seconds())), 1)
+}
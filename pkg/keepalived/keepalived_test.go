@@ -0,0 +1,174 @@
+package keepalived
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/heathcliff26/valkey-keepalived/pkg/config"
+	failoverclient "github.com/heathcliff26/valkey-keepalived/pkg/failover-client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestConfig() config.Config {
+	cfg := config.DefaultConfig()
+	cfg.Valkey.VirtualAddress = "10.8.0.10"
+	cfg.Valkey.Nodes = []failoverclient.NodeConfig{
+		{Address: "10.8.0.11"},
+		{Name: "node2", Address: "10.8.0.12"},
+		{Address: "10.8.0.13", Port: 6380},
+	}
+	cfg.Notify.Enabled = true
+	return cfg
+}
+
+func newTestOptions(node string) Options {
+	return Options{
+		Node:            node,
+		Instance:        DEFAULT_INSTANCE,
+		Interface:       DEFAULT_INTERFACE,
+		VirtualRouterID: DEFAULT_VIRTUAL_ROUTER_ID,
+		Binary:          DEFAULT_BINARY,
+		ConfigPath:      "/etc/valkey-keepalived/config.yaml",
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	require := require.New(t)
+
+	expected, err := os.ReadFile("testdata/node2.conf")
+	require.NoError(err, "Should read expected config")
+
+	var out bytes.Buffer
+	err = Generate(&out, newTestConfig(), newTestOptions("node2"))
+	require.NoError(err, "Should render config")
+	assert.Equal(t, string(expected), out.String(), "Should render the default template")
+}
+
+func TestGenerateCustomTemplate(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	opts := newTestOptions("10.8.0.13:6380")
+	opts.Env = true
+	opts.Template = "{{ .State }} {{ .Node.Priority }} {{ .Weight }}\n{{ range .Nodes }}{{ .Host }}:{{ .Port }}={{ .Priority }}\n{{ end }}{{ .CheckCommand }}"
+
+	var out bytes.Buffer
+	err := Generate(&out, newTestConfig(), opts)
+	require.NoError(err, "Should render config")
+
+	lines := bytes.Split(out.Bytes(), []byte("\n"))
+	require.Len(lines, 5, "Should render all nodes")
+	assert.Equal("BACKUP 180 -30", string(lines[0]), "Should derive the priority from the order of the nodes")
+	assert.Equal("10.8.0.11:6379=200", string(lines[1]), "Should give the first node the highest priority")
+	assert.Equal("10.8.0.13:6380=180", string(lines[3]))
+	assert.Contains(string(lines[4]), "--node 10.8.0.13:6380", "Should reference nodes without name by address")
+	assert.Contains(string(lines[4]), "--env", "Should pass --env to the check")
+}
+
+func TestNewData(t *testing.T) {
+	t.Run("Master", func(t *testing.T) {
+		assert := assert.New(t)
+
+		cfg := newTestConfig()
+		cfg.Notify.Enabled = false
+		cfg.Valkey.CheckInterval = 100 * time.Millisecond
+		cfg.Valkey.Timeout = 2500 * time.Millisecond
+
+		data, err := newData(cfg, newTestOptions("10.8.0.11"))
+		assert.NoError(err, "Should find the node")
+		assert.Equal("MASTER", data.State, "Should start the first node as master")
+		assert.Equal(int64(200), data.Node.Priority)
+		assert.Equal(int64(1), data.Interval, "Should check at most once per second")
+		assert.Equal(int64(4), data.Timeout, "Should round up the timeout and leave time for the check")
+		assert.Empty(data.NotifyCommand, "Should not notify when the socket is disabled")
+	})
+	t.Run("ManyNodes", func(t *testing.T) {
+		assert := assert.New(t)
+
+		cfg := newTestConfig()
+		cfg.Valkey.Nodes = make([]failoverclient.NodeConfig, 30)
+		for i := range cfg.Valkey.Nodes {
+			cfg.Valkey.Nodes[i].Name = string(rune('a' + i))
+		}
+
+		data, err := newData(cfg, newTestOptions(cfg.Valkey.Nodes[29].Name))
+		assert.NoError(err, "Should find the node")
+		assert.Equal(int64(1), data.Node.Priority, "Should not go below the minimum priority")
+		assert.Equal(int64(-253), data.Weight, "Should not go beyond the maximum weight")
+	})
+
+	tMatrix := map[string]struct {
+		modify func(cfg *config.Config, opts *Options)
+		err    string
+	}{
+		"UnknownNode": {
+			modify: func(_ *config.Config, opts *Options) {
+				opts.Node = "unknown"
+			},
+			err: "node \"unknown\" is not part of the config",
+		},
+		"HostnameVirtualAddress": {
+			modify: func(cfg *config.Config, _ *Options) {
+				cfg.Valkey.VirtualAddress = "valkey.example.com"
+			},
+			err: "virtual address \"valkey.example.com\" needs to be an ip to be used by keepalived",
+		},
+		"InvalidVirtualRouterID": {
+			modify: func(_ *config.Config, opts *Options) {
+				opts.VirtualRouterID = 256
+			},
+			err: "invalid virtual router id, needs to be between 1-255",
+		},
+		"ConfigPathWithSpace": {
+			modify: func(_ *config.Config, opts *Options) {
+				opts.ConfigPath = "/etc/valkey keepalived/config.yaml"
+			},
+			err: "script argument \"/etc/valkey keepalived/config.yaml\" can't be empty or contain whitespace or quotes",
+		},
+		"NodeNameWithQuote": {
+			modify: func(cfg *config.Config, opts *Options) {
+				cfg.Valkey.Nodes[1].Name = "node\"2"
+				opts.Node = "node\"2"
+			},
+			err: "script argument \"node\"2\" can't be empty or contain whitespace or quotes",
+		},
+		"NotifySocketWithSpace": {
+			modify: func(cfg *config.Config, _ *Options) {
+				cfg.Notify.Socket = "/run/valkey keepalived.sock"
+			},
+			err: "script argument \"/run/valkey keepalived.sock\" can't be empty or contain whitespace or quotes",
+		},
+		"EmptyBinary": {
+			modify: func(_ *config.Config, opts *Options) {
+				opts.Binary = ""
+			},
+			err: "script argument \"\" can't be empty or contain whitespace or quotes",
+		},
+	}
+
+	for name, tCase := range tMatrix {
+		t.Run(name, func(t *testing.T) {
+			cfg := newTestConfig()
+			opts := newTestOptions("node2")
+			tCase.modify(&cfg, &opts)
+
+			_, err := newData(cfg, opts)
+			assert.EqualError(t, err, tCase.err)
+		})
+	}
+}
+
+func TestGenerateInvalidTemplate(t *testing.T) {
+	opts := newTestOptions("node2")
+
+	opts.Template = "{{ .Unknown"
+	err := Generate(&bytes.Buffer{}, newTestConfig(), opts)
+	assert.ErrorContains(t, err, "failed to parse template", "Should fail on invalid syntax")
+
+	opts.Template = "{{ .Unknown }}"
+	err = Generate(&bytes.Buffer{}, newTestConfig(), opts)
+	assert.ErrorContains(t, err, "failed to render template", "Should fail on unknown fields")
+}
@@ -0,0 +1,21 @@
+package notify
+
+import "fmt"
+
+const DEFAULT_SOCKET = "/run/valkey-keepalived/notify.sock"
+
+type Config struct {
+	Enabled bool   `yaml:"enabled,omitempty"`
+	Socket  string `yaml:"socket,omitempty"`
+}
+
+// Ensure that the given config is valid
+func (c Config) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.Socket == "" {
+		return fmt.Errorf("missing notify socket")
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package notify
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigValidate(t *testing.T) {
+	tMatrix := []struct {
+		Name   string
+		Config Config
+		Valid  bool
+	}{
+		{
+			Name:   "Disabled",
+			Config: Config{},
+			Valid:  true,
+		},
+		{
+			Name:   "ValidSocket",
+			Config: Config{Enabled: true, Socket: DEFAULT_SOCKET},
+			Valid:  true,
+		},
+		{
+			Name:   "MissingSocket",
+			Config: Config{Enabled: true},
+			Valid:  false,
+		},
+	}
+
+	for _, tCase := range tMatrix {
+		t.Run(tCase.Name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			if tCase.Valid {
+				assert.NoError(tCase.Config.Validate())
+			} else {
+				assert.Error(tCase.Config.Validate())
+			}
+		})
+	}
+}
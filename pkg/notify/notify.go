@@ -0,0 +1,138 @@
+package notify
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// How long a connected client has to send its notification
+	readTimeout = 5 * time.Second
+	// Longest notification accepted, the events of keepalived are far below it
+	maxEventLength = 4 * 1024
+
+	responseOK = "ok"
+)
+
+// Listens on a unix socket for notifications, e.g. from the notify scripts of keepalived
+type Listener struct {
+	path     string
+	listener net.Listener
+	onNotify func()
+}
+
+// Create the socket at the given path, replacing a socket left behind by a previous process.
+// Calls onNotify for every notification received.
+func NewListener(path string, onNotify func()) (*Listener, error) {
+	err := os.MkdirAll(filepath.Dir(path), 0750)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create socket directory: %w", err)
+	}
+
+	fi, err := os.Lstat(path)
+	if err == nil && fi.Mode()&os.ModeSocket != 0 {
+		err = os.Remove(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to remove old socket: %w", err)
+		}
+	}
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	// #nosec G302: The socket needs to be writable by the group, so keepalived can use it when running as a different user.
+	err = os.Chmod(path, 0660)
+	if err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to set socket permissions: %w", err)
+	}
+
+	return &Listener{
+		path:     path,
+		listener: listener,
+		onNotify: onNotify,
+	}, nil
+}
+
+// Accept notifications, blocks until the listener is closed
+func (l *Listener) Run() {
+	slog.Info("Listening for notifications", slog.String("socket", l.path))
+	for {
+		conn, err := l.listener.Accept()
+		if errors.Is(err, net.ErrClosed) {
+			return
+		}
+		if err != nil {
+			slog.Error("Failed to accept notification", "err", err)
+			continue
+		}
+		go l.handle(conn)
+	}
+}
+
+// Read the notification from the connection and confirm it
+func (l *Listener) handle(conn net.Conn) {
+	defer conn.Close()
+
+	_ = conn.SetDeadline(time.Now().Add(readTimeout))
+	event, err := bufio.NewReaderSize(conn, maxEventLength).ReadSlice('\n')
+	if errors.Is(err, bufio.ErrBufferFull) {
+		slog.Warn("Ignoring notification exceeding the maximum length", slog.Int("max", maxEventLength))
+		return
+	}
+	if err != nil && !errors.Is(err, io.EOF) {
+		slog.Warn("Failed to read notification", "err", err)
+		return
+	}
+
+	slog.Info("Received notification", slog.String("event", strings.TrimSpace(string(event))))
+	l.onNotify()
+
+	_, err = conn.Write([]byte(responseOK + "\n"))
+	if err != nil {
+		slog.Debug("Failed to confirm notification", "err", err)
+	}
+}
+
+// Stop listening and remove the socket
+func (l *Listener) Close() error {
+	return l.listener.Close()
+}
+
+// Send the event to the listener on the given socket and wait for the confirmation
+func Send(path, event string, timeout time.Duration) error {
+	conn, err := net.DialTimeout("unix", path, timeout)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	err = conn.SetDeadline(time.Now().Add(timeout))
+	if err != nil {
+		return err
+	}
+
+	event = strings.ReplaceAll(event, "\n", " ")
+	_, err = conn.Write([]byte(event + "\n"))
+	if err != nil {
+		return fmt.Errorf("failed to send notification: %w", err)
+	}
+
+	res, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("failed to read confirmation: %w", err)
+	}
+	if strings.TrimSpace(res) != responseOK {
+		return fmt.Errorf("unexpected response \"%s\"", strings.TrimSpace(res))
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package notify
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Start a listener on a socket in a temporary directory and count the notifications
+func newTestListener(t *testing.T) (string, chan struct{}) {
+	path := filepath.Join(t.TempDir(), "run", "notify.sock")
+	notified := make(chan struct{}, 10)
+
+	l, err := NewListener(path, func() {
+		notified <- struct{}{}
+	})
+	require.NoError(t, err, "Should create listener")
+
+	done := make(chan struct{})
+	go func() {
+		l.Run()
+		close(done)
+	}()
+	t.Cleanup(func() {
+		assert.NoError(t, l.Close(), "Should close listener")
+		<-done
+	})
+
+	return path, notified
+}
+
+func TestSend(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	path, notified := newTestListener(t)
+
+	fi, err := os.Stat(path)
+	require.NoError(err, "Should create the socket")
+	assert.Equal(os.FileMode(0660), fi.Mode().Perm(), "Should set the socket permissions")
+
+	require.NoError(Send(path, "INSTANCE VI_1 MASTER 100", time.Second), "Should send notification")
+	select {
+	case <-notified:
+	case <-time.After(time.Second):
+		t.Fatal("Should call onNotify")
+	}
+
+	require.NoError(Send(path, "", time.Second), "Should send notification without event")
+	assert.Len(notified, 1, "Should call onNotify for every notification")
+}
+
+func TestSendTooLong(t *testing.T) {
+	path, notified := newTestListener(t)
+
+	err := Send(path, strings.Repeat("x", maxEventLength), time.Second)
+	assert.Error(t, err, "Should not confirm a notification exceeding the maximum length")
+	assert.Empty(t, notified, "Should not call onNotify")
+}
+
+func TestSendNoListener(t *testing.T) {
+	err := Send(filepath.Join(t.TempDir(), "notify.sock"), "master", time.Second)
+	assert.Error(t, err, "Should fail without listener")
+}
+
+func TestSendUnexpectedResponse(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notify.sock")
+	l, err := net.Listen("unix", path)
+	require.NoError(t, err, "Should listen")
+	t.Cleanup(func() {
+		l.Close()
+	})
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_, _ = conn.Write([]byte("error\n"))
+	}()
+
+	err = Send(path, "master", time.Second)
+	assert.ErrorContains(t, err, "unexpected response", "Should fail on unexpected response")
+}
+
+func TestNewListenerReplacesOldSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notify.sock")
+
+	old, err := net.Listen("unix", path)
+	require.NoError(t, err, "Should listen")
+	// Leave the socket file behind, as a crashed process would
+	old.(*net.UnixListener).SetUnlinkOnClose(false)
+	require.NoError(t, old.Close(), "Should close old listener")
+
+	l, err := NewListener(path, func() {})
+	require.NoError(t, err, "Should replace the old socket")
+	require.NoError(t, l.Close(), "Should close listener")
+
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err), "Should remove the socket on close")
+}
+
+func TestNewListenerNoSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notify.sock")
+	require.NoError(t, os.WriteFile(path, []byte("data"), 0600), "Should create file")
+
+	_, err := NewListener(path, func() {})
+	assert.Error(t, err, "Should not replace a regular file")
+}
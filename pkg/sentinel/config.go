@@ -0,0 +1,38 @@
+package sentinel
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	DEFAULT_PORT        = 26379
+	DEFAULT_MASTER_NAME = "mymaster"
+)
+
+type Config struct {
+	Enabled bool `yaml:"enabled,omitempty"`
+	// Address to listen on, listens on all addresses when empty
+	Address string `yaml:"address,omitempty"`
+	Port    int64  `yaml:"port,omitempty"`
+	// The name clients use to ask for the master
+	MasterName string `yaml:"masterName,omitempty"`
+}
+
+// Ensure that the given config is valid
+func (c Config) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.Port < 0 || c.Port > 65535 {
+		return fmt.Errorf("invalid sentinel port, needs to be between 0-65535")
+	}
+	if c.MasterName == "" {
+		return fmt.Errorf("missing sentinel master name")
+	}
+	// The name is part of space separated pub/sub messages
+	if strings.ContainsAny(c.MasterName, " \t\r\n") {
+		return fmt.Errorf("invalid sentinel master name, can't contain whitespace")
+	}
+	return nil
+}
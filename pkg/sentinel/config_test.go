@@ -0,0 +1,53 @@
+package sentinel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigValidate(t *testing.T) {
+	tMatrix := []struct {
+		Name   string
+		Config Config
+		Valid  bool
+	}{
+		{
+			Name:   "Disabled",
+			Config: Config{Port: -1},
+			Valid:  true,
+		},
+		{
+			Name:   "Valid",
+			Config: Config{Enabled: true, Port: DEFAULT_PORT, MasterName: DEFAULT_MASTER_NAME},
+			Valid:  true,
+		},
+		{
+			Name:   "InvalidPort",
+			Config: Config{Enabled: true, Port: 65536, MasterName: DEFAULT_MASTER_NAME},
+			Valid:  false,
+		},
+		{
+			Name:   "MissingMasterName",
+			Config: Config{Enabled: true, Port: DEFAULT_PORT},
+			Valid:  false,
+		},
+		{
+			Name:   "WhitespaceInMasterName",
+			Config: Config{Enabled: true, Port: DEFAULT_PORT, MasterName: "my master"},
+			Valid:  false,
+		},
+	}
+
+	for _, tCase := range tMatrix {
+		t.Run(tCase.Name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			if tCase.Valid {
+				assert.NoError(tCase.Config.Validate())
+			} else {
+				assert.Error(tCase.Config.Validate())
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package sentinel
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	// Limits for the commands accepted from clients, the sentinel commands are far below them
+	maxArgs       = 1024
+	maxArgLength  = 64 * 1024
+	maxLineLength = 64 * 1024
+)
+
+// Create a reader for client commands, its buffer limits the length of a line
+func newReader(r io.Reader) *bufio.Reader {
+	return bufio.NewReaderSize(r, maxLineLength)
+}
+
+// Read a single command, either as array of bulk strings or as inline command
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(line, "*") {
+		return strings.Fields(line), nil
+	}
+
+	n, err := strconv.Atoi(line[1:])
+	if err != nil || n > maxArgs {
+		return nil, fmt.Errorf("invalid multibulk length")
+	}
+	args := make([]string, 0, max(n, 0))
+	for range n {
+		line, err = readLine(r)
+		if err != nil {
+			return nil, err
+		}
+		if !strings.HasPrefix(line, "$") {
+			return nil, fmt.Errorf("expected '$', got '%s'", line)
+		}
+		length, err := strconv.Atoi(line[1:])
+		if err != nil || length < 0 || length > maxArgLength {
+			return nil, fmt.Errorf("invalid bulk length")
+		}
+		buf := make([]byte, length+2)
+		_, err = io.ReadFull(r, buf)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, string(buf[:length]))
+	}
+	return args, nil
+}
+
+// Read a line terminated by \r\n or \n, without the terminator.
+// Fails when the line does not fit into the buffer of the reader.
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadSlice('\n')
+	if errors.Is(err, bufio.ErrBufferFull) {
+		return "", fmt.Errorf("line too long")
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(line), "\r\n"), nil
+}
+
+// Buffered writer for RESP2 replies
+type writer struct {
+	*bufio.Writer
+}
+
+func (w writer) simpleString(s string) {
+	_, _ = w.WriteString("+" + s + "\r\n")
+}
+
+func (w writer) error(s string) {
+	_, _ = w.WriteString("-" + s + "\r\n")
+}
+
+func (w writer) integer(i int) {
+	_, _ = w.WriteString(":" + strconv.Itoa(i) + "\r\n")
+}
+
+func (w writer) bulk(s string) {
+	_, _ = w.WriteString("$" + strconv.Itoa(len(s)) + "\r\n" + s + "\r\n")
+}
+
+func (w writer) nullBulk() {
+	_, _ = w.WriteString("$-1\r\n")
+}
+
+func (w writer) arrayLen(n int) {
+	_, _ = w.WriteString("*" + strconv.Itoa(n) + "\r\n")
+}
+
+func (w writer) nullArray() {
+	_, _ = w.WriteString("*-1\r\n")
+}
+
+// Write an array of bulk strings
+func (w writer) strings(s ...string) {
+	w.arrayLen(len(s))
+	for _, v := range s {
+		w.bulk(v)
+	}
+}
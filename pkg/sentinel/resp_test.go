@@ -0,0 +1,96 @@
+package sentinel
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadCommand(t *testing.T) {
+	tMatrix := map[string]struct {
+		input string
+		args  []string
+		err   bool
+	}{
+		"Multibulk": {
+			input: "*3\r\n$8\r\nSENTINEL\r\n$23\r\nget-master-addr-by-name\r\n$8\r\nmymaster\r\n",
+			args:  []string{"SENTINEL", "get-master-addr-by-name", "mymaster"},
+		},
+		"EmptyArgument": {
+			input: "*2\r\n$4\r\nPING\r\n$0\r\n\r\n",
+			args:  []string{"PING", ""},
+		},
+		"Inline": {
+			input: "sentinel masters\r\n",
+			args:  []string{"sentinel", "masters"},
+		},
+		"InlineWithoutCarriageReturn": {
+			input: "PING\n",
+			args:  []string{"PING"},
+		},
+		"InvalidLength": {
+			input: "*x\r\n",
+			err:   true,
+		},
+		"TooManyArguments": {
+			input: "*1025\r\n",
+			err:   true,
+		},
+		"MissingBulk": {
+			input: "*1\r\nPING\r\n",
+			err:   true,
+		},
+		"BulkTooLong": {
+			input: "*1\r\n$65537\r\n",
+			err:   true,
+		},
+		"Truncated": {
+			input: "*1\r\n$4\r\nPI",
+			err:   true,
+		},
+		"InlineTooLong": {
+			input: strings.Repeat("a", maxLineLength+1),
+			err:   true,
+		},
+		"LengthTooLong": {
+			input: "*" + strings.Repeat("0", maxLineLength) + "1\r\n",
+			err:   true,
+		},
+	}
+
+	for name, tCase := range tMatrix {
+		t.Run(name, func(t *testing.T) {
+			args, err := readCommand(newReader(strings.NewReader(tCase.input)))
+			if tCase.err {
+				assert.Error(t, err, "Should fail to read the command")
+				return
+			}
+			assert.NoError(t, err, "Should read the command")
+			assert.Equal(t, tCase.args, args)
+		})
+	}
+}
+
+func TestReadLineTooLong(t *testing.T) {
+	// Without a newline the whole input would be buffered by an unbounded read
+	_, err := readLine(newReader(strings.NewReader(strings.Repeat("a", 2*maxLineLength))))
+	assert.EqualError(t, err, "line too long", "Should reject lines not fitting into the buffer")
+}
+
+func TestWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := writer{bufio.NewWriter(&buf)}
+
+	w.simpleString("OK")
+	w.error("ERR failed")
+	w.integer(2)
+	w.strings("10.8.0.11", "6379")
+	w.nullBulk()
+	w.nullArray()
+	assert.NoError(t, w.Flush(), "Should flush")
+
+	assert.Equal(t, "+OK\r\n-ERR failed\r\n:2\r\n*2\r\n$9\r\n10.8.0.11\r\n$4\r\n6379\r\n$-1\r\n*-1\r\n", buf.String())
+}
@@ -0,0 +1,408 @@
+package sentinel
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"net"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	failoverclient "github.com/heathcliff26/valkey-keepalived/pkg/failover-client"
+)
+
+const (
+	// Channel the switch of the master is published on
+	channelSwitchMaster = "+switch-master"
+
+	// How long a client has to accept a reply or message before it is disconnected
+	writeTimeout = 5 * time.Second
+)
+
+// The state of the failover client exposed to the clients
+type FailoverClient interface {
+	Status() failoverclient.Status
+}
+
+// Implements the subset of the sentinel protocol clients need to discover the master
+type Server struct {
+	masterName string
+	client     FailoverClient
+	listener   net.Listener
+
+	lock  sync.Mutex
+	conns map[*conn]struct{}
+}
+
+// A connected client
+type conn struct {
+	net.Conn
+
+	// Guards the writer and subscriptions, as messages are published from other goroutines
+	lock     sync.Mutex
+	w        writer
+	channels map[string]bool
+	patterns map[string]bool
+}
+
+// Listen on the configured address and port, answering with the master and nodes known to the given client
+func NewServer(cfg Config, client FailoverClient) (*Server, error) {
+	listener, err := net.Listen("tcp", net.JoinHostPort(cfg.Address, strconv.FormatInt(cfg.Port, 10)))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Server{
+		masterName: cfg.MasterName,
+		client:     client,
+		listener:   listener,
+		conns:      make(map[*conn]struct{}),
+	}, nil
+}
+
+// The address the server is listening on
+func (s *Server) Addr() net.Addr {
+	return s.listener.Addr()
+}
+
+// Accept clients, blocks until the server is closed
+func (s *Server) Run() {
+	slog.Info("Starting sentinel listener", slog.String("addr", s.listener.Addr().String()))
+	for {
+		c, err := s.listener.Accept()
+		if errors.Is(err, net.ErrClosed) {
+			return
+		}
+		if err != nil {
+			slog.Error("Failed to accept sentinel client", "err", err)
+			continue
+		}
+		go s.handle(c)
+	}
+}
+
+// Stop listening and disconnect all clients
+func (s *Server) Close() error {
+	err := s.listener.Close()
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for c := range s.conns {
+		c.Close()
+	}
+	return err
+}
+
+// Publish the switch of the master to all subscribed clients
+func (s *Server) PublishSwitchover(from, to failoverclient.MasterState) {
+	msg := strings.Join([]string{s.masterName, from.Address, strconv.FormatInt(from.Port, 10), to.Address, strconv.FormatInt(to.Port, 10)}, " ")
+	slog.Debug("Publishing switch of the master to sentinel clients", slog.String("message", msg))
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for c := range s.conns {
+		// Do not block the failover loop on slow clients
+		go c.publish(channelSwitchMaster, msg)
+	}
+}
+
+// Serve the commands of a single client until it disconnects
+func (s *Server) handle(nc net.Conn) {
+	c := &conn{
+		Conn:     nc,
+		w:        writer{bufio.NewWriter(nc)},
+		channels: make(map[string]bool),
+		patterns: make(map[string]bool),
+	}
+
+	s.lock.Lock()
+	s.conns[c] = struct{}{}
+	s.lock.Unlock()
+	defer func() {
+		s.lock.Lock()
+		delete(s.conns, c)
+		s.lock.Unlock()
+		c.Close()
+	}()
+
+	r := newReader(nc)
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			if !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+				slog.Debug("Disconnecting sentinel client", slog.String("client", nc.RemoteAddr().String()), "err", err)
+			}
+			return
+		}
+		if len(args) == 0 {
+			continue
+		}
+
+		c.lock.Lock()
+		quit := s.execute(c, args)
+		_ = c.SetWriteDeadline(time.Now().Add(writeTimeout))
+		err = c.w.Flush()
+		c.lock.Unlock()
+		if quit || err != nil {
+			return
+		}
+	}
+}
+
+// Execute the command and write the reply.
+// Returns true when the connection should be closed.
+func (s *Server) execute(c *conn, args []string) bool {
+	cmd := strings.ToUpper(args[0])
+	if c.subscribed() {
+		switch cmd {
+		case "SUBSCRIBE", "PSUBSCRIBE", "UNSUBSCRIBE", "PUNSUBSCRIBE", "PING", "QUIT":
+		default:
+			c.w.error(fmt.Sprintf("ERR Can't execute '%s': only (P)SUBSCRIBE / (P)UNSUBSCRIBE / PING / QUIT are allowed in this context", strings.ToLower(cmd)))
+			return false
+		}
+	}
+
+	switch cmd {
+	case "PING":
+		switch {
+		case c.subscribed():
+			c.w.strings("pong", strings.Join(args[1:], " "))
+		case len(args) > 1:
+			c.w.bulk(args[1])
+		default:
+			c.w.simpleString("PONG")
+		}
+	case "QUIT":
+		c.w.simpleString("OK")
+		return true
+	case "CLIENT":
+		// Client libraries set their name and version after connecting
+		if len(args) > 1 && (strings.EqualFold(args[1], "SETNAME") || strings.EqualFold(args[1], "SETINFO")) {
+			c.w.simpleString("OK")
+		} else {
+			c.w.error("ERR unknown subcommand for 'client'")
+		}
+	case "SENTINEL":
+		s.sentinel(c.w, args[1:])
+	case "SUBSCRIBE", "PSUBSCRIBE":
+		if len(args) < 2 {
+			c.w.error(fmt.Sprintf("ERR wrong number of arguments for '%s' command", strings.ToLower(cmd)))
+			return false
+		}
+		for _, name := range args[1:] {
+			c.subscriptions(cmd)[name] = true
+			c.w.arrayLen(3)
+			c.w.bulk(strings.ToLower(cmd))
+			c.w.bulk(name)
+			c.w.integer(c.count())
+		}
+	case "UNSUBSCRIBE", "PUNSUBSCRIBE":
+		subs := c.subscriptions(cmd)
+		names := args[1:]
+		if len(names) == 0 {
+			names = slices.Sorted(maps.Keys(subs))
+		}
+		if len(names) == 0 {
+			c.w.arrayLen(3)
+			c.w.bulk(strings.ToLower(cmd))
+			c.w.nullBulk()
+			c.w.integer(c.count())
+		}
+		for _, name := range names {
+			delete(subs, name)
+			c.w.arrayLen(3)
+			c.w.bulk(strings.ToLower(cmd))
+			c.w.bulk(name)
+			c.w.integer(c.count())
+		}
+	default:
+		c.w.error(fmt.Sprintf("ERR unknown command '%s', with args beginning with: %s", args[0], strings.Join(args[1:], " ")))
+	}
+	return false
+}
+
+// Answer the SENTINEL subcommands
+func (s *Server) sentinel(w writer, args []string) {
+	if len(args) == 0 {
+		w.error("ERR wrong number of arguments for 'sentinel' command")
+		return
+	}
+	sub := strings.ToLower(args[0])
+
+	status := s.client.Status()
+	if sub == "masters" {
+		if status.PromotedMaster == nil {
+			w.arrayLen(0)
+			return
+		}
+		w.arrayLen(1)
+		s.writeMaster(w, status)
+		return
+	}
+
+	switch sub {
+	case "get-master-addr-by-name", "master", "replicas", "slaves", "sentinels":
+	default:
+		w.error(fmt.Sprintf("ERR Unknown sentinel subcommand '%s'", args[0]))
+		return
+	}
+	if len(args) != 2 {
+		w.error(fmt.Sprintf("ERR wrong number of arguments for 'sentinel|%s' command", sub))
+		return
+	}
+	if args[1] != s.masterName {
+		if sub == "get-master-addr-by-name" {
+			w.nullArray()
+		} else {
+			w.error("ERR No such master with that name")
+		}
+		return
+	}
+
+	switch sub {
+	case "get-master-addr-by-name":
+		if status.PromotedMaster == nil {
+			w.nullArray()
+			return
+		}
+		w.strings(status.PromotedMaster.Address, strconv.FormatInt(status.PromotedMaster.Port, 10))
+	case "master":
+		if status.PromotedMaster == nil {
+			w.error("ERR No such master with that name")
+			return
+		}
+		s.writeMaster(w, status)
+	case "replicas", "slaves":
+		replicas := replicas(status)
+		w.arrayLen(len(replicas))
+		for _, n := range replicas {
+			writeReplica(w, n, status.PromotedMaster)
+		}
+	case "sentinels":
+		// There are no other sentinels, the failover client is the only instance
+		w.arrayLen(0)
+	}
+}
+
+// Write the state of the master as list of key value pairs
+func (s *Server) writeMaster(w writer, status failoverclient.Status) {
+	flags := "master"
+	for _, n := range status.Nodes {
+		if n.Address == status.PromotedMaster.Address && n.Port == status.PromotedMaster.Port && !n.Up {
+			flags += ",s_down"
+		}
+	}
+	w.strings(
+		"name", s.masterName,
+		"ip", status.PromotedMaster.Address,
+		"port", strconv.FormatInt(status.PromotedMaster.Port, 10),
+		"runid", status.PromotedMaster.RunID,
+		"flags", flags,
+		"role-reported", "master",
+		"num-slaves", strconv.Itoa(len(replicas(status))),
+		"num-other-sentinels", "0",
+		"quorum", "1",
+	)
+}
+
+// Write the state of a replica as list of key value pairs
+func writeReplica(w writer, n failoverclient.NodeStatus, master *failoverclient.MasterState) {
+	port := strconv.FormatInt(n.Port, 10)
+	res := []string{
+		"name", net.JoinHostPort(n.Address, port),
+		"ip", n.Address,
+		"port", port,
+		"runid", n.RunID,
+	}
+	if n.Up {
+		res = append(res, "flags", "slave")
+	} else {
+		var downTime time.Duration
+		if !n.LastErrorTime.IsZero() {
+			downTime = time.Since(n.LastErrorTime)
+		}
+		res = append(res, "flags", "slave,s_down", "s-down-time", strconv.FormatInt(downTime.Milliseconds(), 10))
+	}
+	linkStatus := "err"
+	if n.LinkStatus == "up" {
+		linkStatus = "ok"
+	}
+	res = append(res,
+		"role-reported", "slave",
+		"master-host", master.Address,
+		"master-port", strconv.FormatInt(master.Port, 10),
+		"master-link-status", linkStatus,
+		"slave-repl-offset", strconv.FormatInt(n.ReplicationOffset, 10),
+	)
+	w.strings(res...)
+}
+
+// Return all nodes besides the master, drained nodes are left out as they are not managed
+func replicas(status failoverclient.Status) []failoverclient.NodeStatus {
+	if status.PromotedMaster == nil {
+		return nil
+	}
+	res := make([]failoverclient.NodeStatus, 0, len(status.Nodes))
+	for _, n := range status.Nodes {
+		if n.Drained || (n.Address == status.PromotedMaster.Address && n.Port == status.PromotedMaster.Port) {
+			continue
+		}
+		res = append(res, n)
+	}
+	return res
+}
+
+// Check if the client is in the subscribed state
+func (c *conn) subscribed() bool {
+	return c.count() > 0
+}
+
+// The number of channels and patterns the client is subscribed to
+func (c *conn) count() int {
+	return len(c.channels) + len(c.patterns)
+}
+
+// Return the channels or patterns affected by the command
+func (c *conn) subscriptions(cmd string) map[string]bool {
+	if strings.HasPrefix(cmd, "P") {
+		return c.patterns
+	}
+	return c.channels
+}
+
+// Send the message to the client, if it is subscribed to the channel
+func (c *conn) publish(channel, msg string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	sent := false
+	if c.channels[channel] {
+		c.w.strings("message", channel, msg)
+		sent = true
+	}
+	for pattern := range c.patterns {
+		if ok, _ := path.Match(pattern, channel); ok {
+			c.w.strings("pmessage", pattern, channel, msg)
+			sent = true
+		}
+	}
+	if !sent {
+		return
+	}
+
+	_ = c.SetWriteDeadline(time.Now().Add(writeTimeout))
+	err := c.w.Flush()
+	if err != nil {
+		slog.Debug("Failed to publish message to sentinel client", slog.String("client", c.RemoteAddr().String()), "err", err)
+		c.Close()
+	}
+}
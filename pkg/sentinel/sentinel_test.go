@@ -0,0 +1,293 @@
+package sentinel
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/alicebob/miniredis/v2/server"
+	failoverclient "github.com/heathcliff26/valkey-keepalived/pkg/failover-client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valkey-io/valkey-go"
+)
+
+type fakeFailoverClient struct {
+	lock   sync.Mutex
+	status failoverclient.Status
+}
+
+func (f *fakeFailoverClient) Status() failoverclient.Status {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.status
+}
+
+func (f *fakeFailoverClient) setStatus(status failoverclient.Status) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.status = status
+}
+
+var testStatus = failoverclient.Status{
+	Master:         &failoverclient.MasterState{RunID: "runid1", Address: "10.8.0.11", Port: 6379},
+	PromotedMaster: &failoverclient.MasterState{RunID: "runid1", Address: "10.8.0.11", Port: 6379},
+	Nodes: []failoverclient.NodeStatus{
+		{Address: "10.8.0.11", Port: 6379, RunID: "runid1", Up: true},
+		{Address: "10.8.0.12", Port: 6379, RunID: "runid2", Up: true, LinkStatus: "up", ReplicationOffset: 100},
+		{Address: "10.8.0.13", Port: 6379},
+		{Address: "10.8.0.14", Port: 6379, Drained: true},
+	},
+}
+
+// Start a server on a random port for the given client
+func newTestServer(t *testing.T, client FailoverClient) *Server {
+	s, err := NewServer(Config{Enabled: true, MasterName: DEFAULT_MASTER_NAME}, client)
+	require.NoError(t, err, "Should create server")
+
+	done := make(chan struct{})
+	go func() {
+		s.Run()
+		close(done)
+	}()
+	t.Cleanup(func() {
+		assert.NoError(t, s.Close(), "Should close server")
+		<-done
+	})
+	return s
+}
+
+func TestNewServerAddress(t *testing.T) {
+	s, err := NewServer(Config{Enabled: true, Address: "127.0.0.1", MasterName: DEFAULT_MASTER_NAME}, &fakeFailoverClient{})
+	require.NoError(t, err, "Should create server")
+	t.Cleanup(func() {
+		s.Close()
+	})
+
+	addr, ok := s.Addr().(*net.TCPAddr)
+	require.True(t, ok, "Should listen on tcp")
+	assert.Equal(t, "127.0.0.1", addr.IP.String(), "Should listen on the configured address")
+}
+
+// Connection sending inline commands and reading the raw replies
+type testConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func dialTestServer(t *testing.T, s *Server) *testConn {
+	c, err := net.Dial("tcp", s.Addr().String())
+	require.NoError(t, err, "Should connect to server")
+	t.Cleanup(func() {
+		c.Close()
+	})
+	return &testConn{Conn: c, r: bufio.NewReader(c)}
+}
+
+// Send the command and return the reply as a single line with the lengths stripped
+func (c *testConn) do(t *testing.T, cmd string) string {
+	_, err := c.Write([]byte(cmd + "\r\n"))
+	require.NoError(t, err, "Should send command")
+	return c.read(t)
+}
+
+// Read a single reply and return its values separated by spaces
+func (c *testConn) read(t *testing.T) string {
+	require.NoError(t, c.SetReadDeadline(time.Now().Add(time.Second)))
+	line, err := c.r.ReadString('\n')
+	require.NoError(t, err, "Should read reply")
+	line = strings.TrimRight(line, "\r\n")
+
+	switch line[0] {
+	case '*':
+		n, _ := strconv.Atoi(line[1:])
+		if n < 0 {
+			return "(nil)"
+		}
+		values := make([]string, 0, n)
+		for range n {
+			values = append(values, c.read(t))
+		}
+		return strings.Join(values, " ")
+	case '$':
+		n, _ := strconv.Atoi(line[1:])
+		if n < 0 {
+			return "(nil)"
+		}
+		buf := make([]byte, n+2)
+		_, err = io.ReadFull(c.r, buf)
+		require.NoError(t, err, "Should read bulk string")
+		return string(buf[:n])
+	case ':':
+		return line[1:]
+	default:
+		return line
+	}
+}
+
+func TestServerCommands(t *testing.T) {
+	s := newTestServer(t, &fakeFailoverClient{status: testStatus})
+
+	tMatrix := map[string]struct {
+		cmd   string
+		reply string
+	}{
+		"Ping":                       {"PING", "+PONG"},
+		"PingMessage":                {"ping hello", "hello"},
+		"ClientSetName":              {"CLIENT SETNAME app", "+OK"},
+		"UnknownCommand":             {"HELLO 3", "-ERR unknown command 'HELLO', with args beginning with: 3"},
+		"GetMasterAddr":              {"SENTINEL get-master-addr-by-name mymaster", "10.8.0.11 6379"},
+		"GetMasterAddrUnknownMaster": {"SENTINEL get-master-addr-by-name other", "(nil)"},
+		"Masters":                    {"SENTINEL masters", "name mymaster ip 10.8.0.11 port 6379 runid runid1 flags master role-reported master num-slaves 2 num-other-sentinels 0 quorum 1"},
+		"Master":                     {"SENTINEL master mymaster", "name mymaster ip 10.8.0.11 port 6379 runid runid1 flags master role-reported master num-slaves 2 num-other-sentinels 0 quorum 1"},
+		"MasterUnknown":              {"SENTINEL master other", "-ERR No such master with that name"},
+		"Sentinels":                  {"SENTINEL sentinels mymaster", ""},
+		"MissingArgument":            {"SENTINEL replicas", "-ERR wrong number of arguments for 'sentinel|replicas' command"},
+		"UnknownSubcommand":          {"SENTINEL failover mymaster", "-ERR Unknown sentinel subcommand 'failover'"},
+	}
+
+	for name, tCase := range tMatrix {
+		t.Run(name, func(t *testing.T) {
+			c := dialTestServer(t, s)
+			assert.Equal(t, tCase.reply, c.do(t, tCase.cmd))
+		})
+	}
+}
+
+func TestServerReplicas(t *testing.T) {
+	assert := assert.New(t)
+
+	s := newTestServer(t, &fakeFailoverClient{status: testStatus})
+	c := dialTestServer(t, s)
+
+	reply := c.do(t, "SENTINEL replicas mymaster")
+	assert.Contains(reply, "name 10.8.0.12:6379 ip 10.8.0.12 port 6379 runid runid2 flags slave role-reported slave master-host 10.8.0.11 master-port 6379 master-link-status ok slave-repl-offset 100", "Should contain the healthy replica")
+	assert.Contains(reply, "name 10.8.0.13:6379 ip 10.8.0.13 port 6379 runid  flags slave,s_down s-down-time 0 role-reported slave", "Should mark the unreachable replica as down")
+	assert.NotContains(reply, "10.8.0.14", "Should leave out drained nodes")
+
+	assert.Equal(reply, c.do(t, "SENTINEL slaves mymaster"), "Should support the old name")
+}
+
+func TestServerNoMaster(t *testing.T) {
+	s := newTestServer(t, &fakeFailoverClient{})
+	c := dialTestServer(t, s)
+
+	assert.Equal(t, "(nil)", c.do(t, "SENTINEL get-master-addr-by-name mymaster"), "Should not return an address")
+	assert.Equal(t, "", c.do(t, "SENTINEL masters"), "Should return no masters")
+	assert.Equal(t, "", c.do(t, "SENTINEL replicas mymaster"), "Should return no replicas")
+}
+
+func TestServerUnpromotedMaster(t *testing.T) {
+	assert := assert.New(t)
+
+	// The master behind the virtual address is not promoted yet, e.g. in dry-run mode or after a failed promotion
+	status := testStatus
+	status.Master = &failoverclient.MasterState{RunID: "runid2", Address: "10.8.0.12", Port: 6379}
+	status.PromotedMaster = nil
+	s := newTestServer(t, &fakeFailoverClient{status: status})
+	c := dialTestServer(t, s)
+
+	assert.Equal("(nil)", c.do(t, "SENTINEL get-master-addr-by-name mymaster"), "Should not return the unpromoted master")
+	assert.Equal("", c.do(t, "SENTINEL masters"), "Should return no masters")
+
+	status.PromotedMaster = testStatus.PromotedMaster
+	s = newTestServer(t, &fakeFailoverClient{status: status})
+	c = dialTestServer(t, s)
+
+	assert.Equal("10.8.0.11 6379", c.do(t, "SENTINEL get-master-addr-by-name mymaster"), "Should keep returning the promoted master")
+}
+
+func TestServerPublishSwitchover(t *testing.T) {
+	assert := assert.New(t)
+
+	s := newTestServer(t, &fakeFailoverClient{status: testStatus})
+	sub := dialTestServer(t, s)
+	psub := dialTestServer(t, s)
+	other := dialTestServer(t, s)
+
+	assert.Equal("subscribe +switch-master 1", sub.do(t, "SUBSCRIBE +switch-master"), "Should subscribe")
+	assert.Equal("subscribe +sdown 2", sub.do(t, "SUBSCRIBE +sdown"), "Should count the subscriptions")
+	assert.Equal("pong ", sub.do(t, "PING"), "Should answer ping in subscribed mode")
+	assert.Contains(sub.do(t, "SENTINEL masters"), "-ERR Can't execute 'sentinel'", "Should refuse other commands in subscribed mode")
+	assert.Equal("psubscribe * 1", psub.do(t, "PSUBSCRIBE *"), "Should subscribe to pattern")
+	assert.Equal("+PONG", other.do(t, "PING"), "Should connect client without subscription")
+
+	s.PublishSwitchover(failoverclient.MasterState{Address: "10.8.0.11", Port: 6379}, failoverclient.MasterState{Address: "10.8.0.12", Port: 6379})
+
+	assert.Equal("message +switch-master mymaster 10.8.0.11 6379 10.8.0.12 6379", sub.read(t), "Should publish the switch to the channel")
+	assert.Equal("pmessage * +switch-master mymaster 10.8.0.11 6379 10.8.0.12 6379", psub.read(t), "Should publish the switch to the pattern")
+	assert.Equal("+PONG", other.do(t, "PING"), "Should not publish to clients without subscription")
+
+	assert.Equal("unsubscribe +sdown 1", sub.do(t, "UNSUBSCRIBE"), "Should unsubscribe from all channels")
+	assert.Equal("unsubscribe +switch-master 0", sub.read(t), "Should unsubscribe from all channels")
+	assert.Equal("+PONG", sub.do(t, "PING"), "Should leave subscribed mode")
+}
+
+func TestServerQuit(t *testing.T) {
+	s := newTestServer(t, &fakeFailoverClient{})
+	c := dialTestServer(t, s)
+
+	assert.Equal(t, "+OK", c.do(t, "QUIT"))
+	_, err := c.r.ReadByte()
+	assert.Error(t, err, "Should close the connection")
+}
+
+// Start a miniredis instance that reports itself as master, as sentinel clients verify the role
+func newTestMaster(t *testing.T) *miniredis.Miniredis {
+	mr := miniredis.RunT(t)
+	err := mr.Server().Register("ROLE", func(c *server.Peer, _ string, _ []string) {
+		c.WriteLen(3)
+		c.WriteBulk("master")
+		c.WriteInt(0)
+		c.WriteLen(0)
+	})
+	require.NoError(t, err, "Should register ROLE command")
+	return mr
+}
+
+func masterState(mr *miniredis.Miniredis) *failoverclient.MasterState {
+	host, port, _ := net.SplitHostPort(mr.Addr())
+	p, _ := strconv.ParseInt(port, 10, 64)
+	return &failoverclient.MasterState{Address: host, Port: p}
+}
+
+func TestServerWithSentinelClient(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	master1 := newTestMaster(t)
+	master2 := newTestMaster(t)
+	require.NoError(master1.Set("key", "master1"))
+	require.NoError(master2.Set("key", "master2"))
+
+	fake := &fakeFailoverClient{status: failoverclient.Status{Master: masterState(master1), PromotedMaster: masterState(master1)}}
+	s := newTestServer(t, fake)
+
+	client, err := valkey.NewClient(valkey.ClientOption{
+		InitAddress:  []string{s.Addr().String()},
+		Sentinel:     valkey.SentinelOption{MasterSet: DEFAULT_MASTER_NAME},
+		DisableCache: true,
+	})
+	require.NoError(err, "Should discover the master through the sentinel")
+	t.Cleanup(client.Close)
+
+	ctx := context.Background()
+	res, err := client.Do(ctx, client.B().Get().Key("key").Build()).ToString()
+	require.NoError(err, "Should query the master")
+	assert.Equal("master1", res, "Should connect to the first master")
+
+	fake.setStatus(failoverclient.Status{Master: masterState(master2), PromotedMaster: masterState(master2)})
+	s.PublishSwitchover(*masterState(master1), *masterState(master2))
+
+	assert.Eventually(func() bool {
+		res, err := client.Do(ctx, client.B().Get().Key("key").Build()).ToString()
+		return err == nil && res == "master2"
+	}, 5*time.Second, 50*time.Millisecond, "Should follow the switch to the new master")
+}
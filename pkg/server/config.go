@@ -0,0 +1,26 @@
+package server
+
+import "fmt"
+
+const DEFAULT_PORT = 8080
+
+type Config struct {
+	Enabled bool `yaml:"enabled,omitempty"`
+	// Address to listen on, listens on all addresses when empty
+	Address string `yaml:"address,omitempty"`
+	Port    int64  `yaml:"port,omitempty"`
+	// Bearer token required by the endpoints changing the state of the failover client.
+	// The endpoints are disabled when empty.
+	AdminToken string `yaml:"adminToken,omitempty"`
+}
+
+// Ensure that the given config is valid
+func (c Config) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.Port < 0 || c.Port > 65535 {
+		return fmt.Errorf("invalid server port, needs to be between 0-65535")
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigValidate(t *testing.T) {
+	tMatrix := []struct {
+		Name   string
+		Config Config
+		Valid  bool
+	}{
+		{
+			Name:   "Disabled",
+			Config: Config{Port: -1},
+			Valid:  true,
+		},
+		{
+			Name:   "ValidPort",
+			Config: Config{Enabled: true, Port: DEFAULT_PORT},
+			Valid:  true,
+		},
+		{
+			Name:   "NegativePortNumber",
+			Config: Config{Enabled: true, Port: -1},
+			Valid:  false,
+		},
+		{
+			Name:   "PortToBig",
+			Config: Config{Enabled: true, Port: 65536},
+			Valid:  false,
+		},
+	}
+
+	for _, tCase := range tMatrix {
+		t.Run(tCase.Name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			if tCase.Valid {
+				assert.NoError(tCase.Config.Validate())
+			} else {
+				assert.Error(tCase.Config.Validate())
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	failoverclient "github.com/heathcliff26/valkey-keepalived/pkg/failover-client"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const shutdownTimeout = 5 * time.Second
+
+// The state of the failover client exposed by the server
+type FailoverClient interface {
+	Healthy() bool
+	Ready() bool
+	Status() failoverclient.Status
+	AcknowledgeSplitBrain()
+	Pause(d time.Duration) failoverclient.Pause
+	Resume()
+}
+
+type Server struct {
+	server     *http.Server
+	client     FailoverClient
+	adminToken string
+}
+
+// Create a new http server exposing the metrics and state of the given client
+func NewServer(cfg Config, client FailoverClient) *Server {
+	s := &Server{
+		client:     client,
+		adminToken: cfg.AdminToken,
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", promhttp.Handler())
+	mux.HandleFunc("GET /healthz", s.healthz)
+	mux.HandleFunc("GET /readyz", s.readyz)
+	mux.HandleFunc("GET /status", s.getStatus)
+	if s.adminToken != "" {
+		mux.HandleFunc("POST /split-brain/ack", s.requireAdminToken(s.ackSplitBrain))
+		mux.HandleFunc("POST /pause", s.requireAdminToken(s.pause))
+		mux.HandleFunc("POST /resume", s.requireAdminToken(s.resume))
+	} else {
+		slog.Debug("No admin token configured, disabling the endpoints changing the state")
+	}
+
+	s.server = &http.Server{
+		Addr:              net.JoinHostPort(cfg.Address, strconv.FormatInt(cfg.Port, 10)),
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	return s
+}
+
+// Start the server, blocks until the server is shut down.
+func (s *Server) Run() error {
+	slog.Info("Starting http server", slog.String("addr", s.server.Addr))
+	err := s.server.ListenAndServe()
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// Gracefully shut down the server
+func (s *Server) Shutdown() error {
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	return s.server.Shutdown(ctx)
+}
+
+// Only pass on requests authenticated with the admin token
+func (s *Server) requireAdminToken(next http.HandlerFunc) http.HandlerFunc {
+	return func(rw http.ResponseWriter, req *http.Request) {
+		token, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.adminToken)) != 1 {
+			rw.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(rw, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next(rw, req)
+	}
+}
+
+// Report if the failover loop is still running
+func (s *Server) healthz(rw http.ResponseWriter, _ *http.Request) {
+	writeProbeResult(rw, s.client.Healthy())
+}
+
+// Report if the master behind the virtual address is known
+func (s *Server) readyz(rw http.ResponseWriter, _ *http.Request) {
+	writeProbeResult(rw, s.client.Ready())
+}
+
+// Return the current view of the failover client as json
+func (s *Server) getStatus(rw http.ResponseWriter, _ *http.Request) {
+	writeJSON(rw, s.client.Status())
+}
+
+// Acknowledge a latched split-brain and return the remaining condition
+func (s *Server) ackSplitBrain(rw http.ResponseWriter, _ *http.Request) {
+	s.client.AcknowledgeSplitBrain()
+	writeJSON(rw, s.client.Status().SplitBrain)
+}
+
+// Pause the reconciliation for the duration given as query parameter and return the pause.
+// Uses the configured pause timeout when no duration is given.
+func (s *Server) pause(rw http.ResponseWriter, req *http.Request) {
+	var d time.Duration
+	if value := req.URL.Query().Get("duration"); value != "" {
+		var err error
+		d, err = time.ParseDuration(value)
+		if err != nil || d < 0 {
+			http.Error(rw, fmt.Sprintf("invalid duration \"%s\"", value), http.StatusBadRequest)
+			return
+		}
+	}
+	writeJSON(rw, s.client.Pause(d))
+}
+
+// Resume the reconciliation and return the remaining pause, which is always null
+func (s *Server) resume(rw http.ResponseWriter, _ *http.Request) {
+	s.client.Resume()
+	writeJSON(rw, s.client.Status().Paused)
+}
+
+func writeProbeResult(rw http.ResponseWriter, ok bool) {
+	if !ok {
+		rw.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = rw.Write([]byte("not ok\n"))
+		return
+	}
+	_, _ = rw.Write([]byte("ok\n"))
+}
+
+func writeJSON(rw http.ResponseWriter, v any) {
+	rw.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(rw).Encode(v)
+	if err != nil {
+		slog.Error("Failed to send response", "err", err)
+	}
+}
@@ -0,0 +1,290 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	failoverclient "github.com/heathcliff26/valkey-keepalived/pkg/failover-client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClient struct {
+	healthy bool
+	ready   bool
+	status  failoverclient.Status
+
+	pausedFor time.Duration
+}
+
+func (f *fakeClient) Healthy() bool {
+	return f.healthy
+}
+
+func (f *fakeClient) Ready() bool {
+	return f.ready
+}
+
+func (f *fakeClient) Status() failoverclient.Status {
+	return f.status
+}
+
+func (f *fakeClient) AcknowledgeSplitBrain() {
+	f.status.SplitBrain = failoverclient.SplitBrain{}
+}
+
+func (f *fakeClient) Pause(d time.Duration) failoverclient.Pause {
+	f.pausedFor = d
+	f.status.Paused = &failoverclient.Pause{Since: time.Unix(100, 0).UTC()}
+	return *f.status.Paused
+}
+
+func (f *fakeClient) Resume() {
+	f.status.Paused = nil
+}
+
+const testAdminToken = "secret"
+
+func TestNewServer(t *testing.T) {
+	s := NewServer(Config{Enabled: true, Port: 9000}, &fakeClient{})
+
+	assert.Equal(t, ":9000", s.server.Addr, "Should listen on the configured port")
+
+	s = NewServer(Config{Enabled: true, Address: "127.0.0.1", Port: 9000}, &fakeClient{})
+	assert.Equal(t, "127.0.0.1:9000", s.server.Addr, "Should listen on the configured address")
+}
+
+func TestMetricsEndpoint(t *testing.T) {
+	assert := assert.New(t)
+
+	s := NewServer(Config{Enabled: true, Port: DEFAULT_PORT}, &fakeClient{})
+
+	rr := serveRequest(s, http.MethodGet, "/metrics")
+
+	assert.Equal(http.StatusOK, rr.Code, "Should return status ok")
+	assert.Contains(rr.Body.String(), "go_goroutines", "Should contain the default metrics")
+}
+
+func TestProbeEndpoints(t *testing.T) {
+	tMatrix := map[string]struct {
+		client     fakeClient
+		path       string
+		expectCode int
+	}{
+		"Healthy": {
+			client:     fakeClient{healthy: true},
+			path:       "/healthz",
+			expectCode: http.StatusOK,
+		},
+		"Unhealthy": {
+			client:     fakeClient{ready: true},
+			path:       "/healthz",
+			expectCode: http.StatusServiceUnavailable,
+		},
+		"Ready": {
+			client:     fakeClient{ready: true},
+			path:       "/readyz",
+			expectCode: http.StatusOK,
+		},
+		"NotReady": {
+			client:     fakeClient{healthy: true},
+			path:       "/readyz",
+			expectCode: http.StatusServiceUnavailable,
+		},
+	}
+
+	for name, tCase := range tMatrix {
+		t.Run(name, func(t *testing.T) {
+			s := NewServer(Config{Enabled: true, Port: DEFAULT_PORT}, &tCase.client)
+
+			rr := serveRequest(s, http.MethodGet, tCase.path)
+
+			assert.Equal(t, tCase.expectCode, rr.Code, "Should return the expected status code")
+		})
+	}
+}
+
+func TestStatusEndpoint(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	client := &fakeClient{
+		status: failoverclient.Status{
+			VirtualAddress: "10.8.0.10",
+			Master: &failoverclient.MasterState{
+				RunID:   "testrunid",
+				Address: "10.8.0.11",
+				Port:    6379,
+			},
+			Nodes: []failoverclient.NodeStatus{
+				{Address: "10.8.0.11", Port: 6379, RunID: "testrunid", Up: true, Role: "master"},
+				{Address: "10.8.0.12", Port: 6379, LastError: "connection refused"},
+			},
+			Ready: true,
+		},
+	}
+	s := NewServer(Config{Enabled: true, Port: DEFAULT_PORT}, client)
+
+	rr := serveRequest(s, http.MethodGet, "/status")
+
+	require.Equal(http.StatusOK, rr.Code, "Should return status ok")
+	assert.Equal("application/json", rr.Header().Get("Content-Type"), "Should return json")
+
+	var res failoverclient.Status
+	require.NoError(json.Unmarshal(rr.Body.Bytes(), &res), "Should return valid json")
+	assert.Equal(client.status, res, "Should return the status of the client")
+}
+
+func TestSplitBrainAckEndpoint(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	client := &fakeClient{
+		status: failoverclient.Status{
+			SplitBrain: failoverclient.SplitBrain{Latched: true, Masters: []string{"node1", "node2"}},
+		},
+	}
+	s := NewServer(Config{Enabled: true, Port: DEFAULT_PORT, AdminToken: testAdminToken}, client)
+
+	rr := serveAdminRequest(s, http.MethodGet, "/split-brain/ack", testAdminToken)
+	assert.Equal(http.StatusMethodNotAllowed, rr.Code, "Should only allow POST")
+	assert.True(client.status.SplitBrain.Latched, "Should not acknowledge on GET")
+
+	rr = serveAdminRequest(s, http.MethodPost, "/split-brain/ack", testAdminToken)
+	require.Equal(http.StatusOK, rr.Code, "Should return status ok")
+
+	var res failoverclient.SplitBrain
+	require.NoError(json.Unmarshal(rr.Body.Bytes(), &res), "Should return valid json")
+	assert.Equal(failoverclient.SplitBrain{}, res, "Should return the remaining condition")
+}
+
+func TestAdminEndpointsAuth(t *testing.T) {
+	tMatrix := map[string]struct {
+		adminToken string
+		token      string
+		expectCode int
+	}{
+		"Disabled": {
+			token:      testAdminToken,
+			expectCode: http.StatusNotFound,
+		},
+		"MissingToken": {
+			adminToken: testAdminToken,
+			expectCode: http.StatusUnauthorized,
+		},
+		"WrongToken": {
+			adminToken: testAdminToken,
+			token:      "wrong",
+			expectCode: http.StatusUnauthorized,
+		},
+		"ValidToken": {
+			adminToken: testAdminToken,
+			token:      testAdminToken,
+			expectCode: http.StatusOK,
+		},
+	}
+
+	for name, tCase := range tMatrix {
+		t.Run(name, func(t *testing.T) {
+			client := &fakeClient{
+				status: failoverclient.Status{
+					SplitBrain: failoverclient.SplitBrain{Latched: true},
+				},
+			}
+			s := NewServer(Config{Enabled: true, Port: DEFAULT_PORT, AdminToken: tCase.adminToken}, client)
+
+			rr := serveAdminRequest(s, http.MethodPost, "/split-brain/ack", tCase.token)
+			assert.Equal(t, tCase.expectCode, rr.Code, "Should return the expected status code")
+			assert.Equal(t, tCase.expectCode != http.StatusOK, client.status.SplitBrain.Latched, "Should only acknowledge authenticated requests")
+		})
+	}
+}
+
+func TestPauseEndpoints(t *testing.T) {
+	tMatrix := map[string]struct {
+		path       string
+		expectCode int
+		expectD    time.Duration
+	}{
+		"Default": {
+			path:       "/pause",
+			expectCode: http.StatusOK,
+		},
+		"Duration": {
+			path:       "/pause?duration=10m",
+			expectCode: http.StatusOK,
+			expectD:    10 * time.Minute,
+		},
+		"InvalidDuration": {
+			path:       "/pause?duration=abc",
+			expectCode: http.StatusBadRequest,
+		},
+		"NegativeDuration": {
+			path:       "/pause?duration=-1m",
+			expectCode: http.StatusBadRequest,
+		},
+	}
+
+	for name, tCase := range tMatrix {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			require := require.New(t)
+
+			client := &fakeClient{}
+			s := NewServer(Config{Enabled: true, Port: DEFAULT_PORT, AdminToken: testAdminToken}, client)
+
+			rr := serveAdminRequest(s, http.MethodPost, tCase.path, testAdminToken)
+			require.Equal(tCase.expectCode, rr.Code, "Should return the expected status code")
+			if tCase.expectCode != http.StatusOK {
+				assert.Nil(client.status.Paused, "Should not pause")
+				return
+			}
+			assert.Equal(tCase.expectD, client.pausedFor, "Should pause for the given duration")
+
+			var res failoverclient.Pause
+			require.NoError(json.Unmarshal(rr.Body.Bytes(), &res), "Should return valid json")
+			assert.Equal(*client.status.Paused, res, "Should return the pause")
+
+			rr = serveAdminRequest(s, http.MethodPost, "/resume", testAdminToken)
+			require.Equal(http.StatusOK, rr.Code, "Should resume")
+			assert.Nil(client.status.Paused, "Should not be paused anymore")
+			assert.Equal("null\n", rr.Body.String(), "Should return the remaining pause")
+		})
+	}
+
+	s := NewServer(Config{Enabled: true, Port: DEFAULT_PORT, AdminToken: testAdminToken}, &fakeClient{})
+	assert.Equal(t, http.StatusMethodNotAllowed, serveAdminRequest(s, http.MethodGet, "/pause", testAdminToken).Code, "Should only allow POST")
+	assert.Equal(t, http.StatusUnauthorized, serveRequest(s, http.MethodPost, "/pause").Code, "Should require the admin token")
+	assert.Equal(t, http.StatusUnauthorized, serveRequest(s, http.MethodPost, "/resume").Code, "Should require the admin token")
+}
+
+func TestServerShutdown(t *testing.T) {
+	s := NewServer(Config{Enabled: true, Port: 0}, &fakeClient{})
+	s.server.Addr = "127.0.0.1:0"
+
+	done := make(chan error)
+	go func() {
+		done <- s.Run()
+	}()
+
+	assert.NoError(t, s.Shutdown(), "Should shut down the server")
+	assert.NoError(t, <-done, "Run should return without error after shutdown")
+}
+
+func serveRequest(s *Server, method, path string) *httptest.ResponseRecorder {
+	return serveAdminRequest(s, method, path, "")
+}
+
+// Serve the request with the given admin token, sends no token when empty
+func serveAdminRequest(s *Server, method, path, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rr := httptest.NewRecorder()
+	s.server.Handler.ServeHTTP(rr, req)
+	return rr
+}
@@ -0,0 +1,103 @@
+package valkeyinfo
+
+import (
+	"strings"
+)
+
+const (
+	SectionServer      = "server"
+	SectionClients     = "clients"
+	SectionMemory      = "memory"
+	SectionPersistence = "persistence"
+	SectionReplication = "replication"
+	SectionKeyspace    = "keyspace"
+)
+
+// Prefix valkey-go keeps when the reply is a RESP3 verbatim string
+const verbatimPrefix = "txt:"
+
+// The parsed output of the INFO command.
+// Sections that were not part of the output are left empty.
+type Info struct {
+	Server      Server
+	Clients     Clients
+	Memory      Memory
+	Persistence Persistence
+	Replication Replication
+	// The databases containing keys, by their index
+	Keyspace map[int]Keyspace
+
+	// All values by section, including the ones without a typed field
+	sections map[string]map[string]string
+}
+
+// Parse the output of the INFO command.
+// Lines that are neither a section header nor a key value pair are ignored.
+func Parse(raw string) Info {
+	raw = strings.TrimPrefix(raw, verbatimPrefix)
+
+	res := Info{
+		sections: make(map[string]map[string]string),
+	}
+
+	section := ""
+	for _, line := range strings.Split(raw, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			section = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(line, "#")))
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		if res.sections[section] == nil {
+			res.sections[section] = make(map[string]string)
+		}
+		res.sections[section][key] = value
+	}
+
+	res.Server = parseServer(res.sections[SectionServer])
+	res.Clients = parseClients(res.sections[SectionClients])
+	res.Memory = parseMemory(res.sections[SectionMemory])
+	res.Persistence = parsePersistence(res.sections[SectionPersistence])
+	res.Replication = parseReplication(res.sections[SectionReplication])
+	res.Keyspace = parseKeyspace(res.sections[SectionKeyspace])
+
+	return res
+}
+
+// Return the number of keys across all databases
+func (i Info) Keys() int64 {
+	var res int64
+	for _, db := range i.Keyspace {
+		res += db.Keys
+	}
+	return res
+}
+
+// Return the raw value of the given key from any section
+func (i Info) Get(key string) (string, bool) {
+	for _, values := range i.sections {
+		if value, ok := values[key]; ok {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// Return the raw values of the given section.
+// Returns nil if the section was not part of the output.
+func (i Info) Section(name string) map[string]string {
+	return i.sections[strings.ToLower(name)]
+}
+
+// Check if the given section was part of the output
+func (i Info) HasSection(name string) bool {
+	_, ok := i.sections[strings.ToLower(name)]
+	return ok
+}
@@ -0,0 +1,118 @@
+package valkeyinfo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testInfoAll = "txt:# Server\r\nvalkey_version:8.1.1\r\nserver_mode:standalone\r\nrun_id:8b2fd4dc5cb8ec3a4b1b6e1b4b1c1f7b6e8e1a9f\r\ntcp_port:6379\r\nuptime_in_seconds:3600\r\n\r\n# Clients\r\nconnected_clients:3\r\nblocked_clients:1\r\nmaxclients:10000\r\n\r\n# Memory\r\nused_memory:1048576\r\nused_memory_rss:2097152\r\nused_memory_peak:4194304\r\nmaxmemory:0\r\nmaxmemory_policy:noeviction\r\n\r\n# Persistence\r\nloading:0\r\nrdb_changes_since_last_save:12\r\nrdb_bgsave_in_progress:1\r\nrdb_last_save_time:1700000000\r\nrdb_last_bgsave_status:ok\r\naof_enabled:1\r\naof_rewrite_in_progress:0\r\naof_last_write_status:ok\r\n\r\n# Replication\r\nrole:master\r\nconnected_slaves:2\r\nslave0:ip=10.88.0.170,port=6379,state=online,offset=1234,lag=0,type=replica\r\nslave1:ip=10.88.0.171,port=6380,state=wait_bgsave,offset=0,lag=1,type=replica\r\nmaster_replid:240bcba5fe13f68d5fa1d9ab84e3e3878b68552a\r\nmaster_repl_offset:1234\r\n"
+
+func TestParse(t *testing.T) {
+	assert := assert.New(t)
+
+	res := Parse(testInfoAll)
+
+	assert.Equal(Server{
+		Version:       "8.1.1",
+		Mode:          "standalone",
+		RunID:         "8b2fd4dc5cb8ec3a4b1b6e1b4b1c1f7b6e8e1a9f",
+		TCPPort:       6379,
+		UptimeSeconds: 3600,
+	}, res.Server, "Should parse server section")
+	assert.Equal(Clients{
+		ConnectedClients: 3,
+		BlockedClients:   1,
+		MaxClients:       10000,
+	}, res.Clients, "Should parse clients section")
+	assert.Equal(Memory{
+		UsedMemory:      1048576,
+		UsedMemoryRSS:   2097152,
+		UsedMemoryPeak:  4194304,
+		MaxMemoryPolicy: "noeviction",
+	}, res.Memory, "Should parse memory section")
+	assert.Equal(Persistence{
+		RDBChangesSinceLastSave: 12,
+		RDBBgsaveInProgress:     true,
+		RDBLastSaveTime:         1700000000,
+		RDBLastBgsaveStatus:     "ok",
+		AOFEnabled:              true,
+		AOFLastWriteStatus:      "ok",
+	}, res.Persistence, "Should parse persistence section")
+	assert.Equal(Replication{
+		Role:                   RoleMaster,
+		MasterLastIOSecondsAgo: -1,
+		ConnectedSlaves:        2,
+		Replicas: []Replica{
+			{IP: "10.88.0.170", Port: 6379, State: "online", Offset: 1234, Lag: 0},
+			{IP: "10.88.0.171", Port: 6380, State: "wait_bgsave", Offset: 0, Lag: 1},
+		},
+		MasterReplID:     "240bcba5fe13f68d5fa1d9ab84e3e3878b68552a",
+		MasterReplOffset: 1234,
+	}, res.Replication, "Should parse replication section")
+}
+
+func TestParseEdgeCases(t *testing.T) {
+	tMatrix := map[string]struct {
+		input string
+		key   string
+		value string
+		found bool
+	}{
+		"Empty": {
+			input: "",
+			key:   "role",
+			found: false,
+		},
+		"NoSection": {
+			input: "role:master\r\nconnected_slaves:0",
+			key:   "role",
+			value: "master",
+			found: true,
+		},
+		"LeadingGarbage": {
+			input: "\r\ntest\r\nrole:master\r\nconnected_slaves:2",
+			key:   "role",
+			value: "master",
+			found: true,
+		},
+		"OnlyNewlines": {
+			input: "# Replication\nrole:slave\nmaster_host:node1\n",
+			key:   "master_host",
+			value: "node1",
+			found: true,
+		},
+		"ValueWithColon": {
+			input: "# Server\r\nexecutable:/usr/bin/valkey-server\r\nconfig_file:C:\\valkey.conf\r\n",
+			key:   "config_file",
+			value: "C:\\valkey.conf",
+			found: true,
+		},
+		"CommentIsNotAKey": {
+			input: "# Keyspace:test\r\n",
+			key:   "# Keyspace",
+			found: false,
+		},
+	}
+
+	for name, tCase := range tMatrix {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			value, found := Parse(tCase.input).Get(tCase.key)
+			assert.Equal(tCase.found, found, "Should return if the key was found")
+			assert.Equal(tCase.value, value, "Should return the value")
+		})
+	}
+}
+
+func TestInfoSection(t *testing.T) {
+	assert := assert.New(t)
+
+	res := Parse(testInfoAll)
+
+	assert.True(res.HasSection("Replication"), "Should ignore the case of the section")
+	assert.Equal("master", res.Section(SectionReplication)["role"], "Should return the raw values")
+	assert.False(res.HasSection("keyspace"), "Should not have sections missing from the output")
+	assert.Nil(res.Section("keyspace"), "Should return nil for missing sections")
+}
@@ -0,0 +1,233 @@
+package valkeyinfo
+
+import (
+	"strconv"
+	"strings"
+)
+
+const (
+	RoleMaster = "master"
+	RoleSlave  = "slave"
+
+	LinkStatusUp   = "up"
+	LinkStatusDown = "down"
+)
+
+type Server struct {
+	Version       string
+	Mode          string
+	RunID         string
+	TCPPort       int64
+	UptimeSeconds int64
+}
+
+type Clients struct {
+	ConnectedClients int64
+	BlockedClients   int64
+	MaxClients       int64
+}
+
+type Memory struct {
+	UsedMemory      int64
+	UsedMemoryRSS   int64
+	UsedMemoryPeak  int64
+	MaxMemory       int64
+	MaxMemoryPolicy string
+}
+
+type Persistence struct {
+	Loading                 bool
+	RDBChangesSinceLastSave int64
+	RDBBgsaveInProgress     bool
+	RDBLastSaveTime         int64
+	RDBLastBgsaveStatus     string
+	AOFEnabled              bool
+	AOFRewriteInProgress    bool
+	AOFLastWriteStatus      string
+}
+
+type Replication struct {
+	Role string
+
+	// Only set for slaves
+	MasterHost             string
+	MasterPort             int64
+	MasterLinkStatus       string
+	MasterLastIOSecondsAgo int64
+	MasterSyncInProgress   bool
+	SlaveReplOffset        int64
+
+	// Only set for masters
+	ConnectedSlaves int64
+	Replicas        []Replica
+
+	MasterReplID     string
+	MasterReplOffset int64
+}
+
+// The keys of a database, as listed in the dbN lines
+type Keyspace struct {
+	Keys    int64
+	Expires int64
+	AvgTTL  int64
+}
+
+// A replica connected to a master, as listed in the slaveN lines
+type Replica struct {
+	IP     string
+	Port   int64
+	State  string
+	Offset int64
+	Lag    int64
+}
+
+// Check if the node is a slave of the given master
+func (r Replication) IsSlaveOf(host string, port int64) bool {
+	return r.Role == RoleSlave && r.MasterHost == host && r.MasterPort == port
+}
+
+// Return the replication offset of the node, regardless of its role
+func (r Replication) Offset() int64 {
+	if r.Role == RoleSlave {
+		return r.SlaveReplOffset
+	}
+	return r.MasterReplOffset
+}
+
+func parseServer(values map[string]string) Server {
+	res := Server{
+		Version:       values["valkey_version"],
+		Mode:          values["server_mode"],
+		RunID:         values["run_id"],
+		TCPPort:       parseInt(values["tcp_port"]),
+		UptimeSeconds: parseInt(values["uptime_in_seconds"]),
+	}
+	// Older versions and redis only report the redis_* keys
+	if res.Version == "" {
+		res.Version = values["redis_version"]
+	}
+	if res.Mode == "" {
+		res.Mode = values["redis_mode"]
+	}
+	return res
+}
+
+func parseClients(values map[string]string) Clients {
+	return Clients{
+		ConnectedClients: parseInt(values["connected_clients"]),
+		BlockedClients:   parseInt(values["blocked_clients"]),
+		MaxClients:       parseInt(values["maxclients"]),
+	}
+}
+
+func parseMemory(values map[string]string) Memory {
+	return Memory{
+		UsedMemory:      parseInt(values["used_memory"]),
+		UsedMemoryRSS:   parseInt(values["used_memory_rss"]),
+		UsedMemoryPeak:  parseInt(values["used_memory_peak"]),
+		MaxMemory:       parseInt(values["maxmemory"]),
+		MaxMemoryPolicy: values["maxmemory_policy"],
+	}
+}
+
+func parsePersistence(values map[string]string) Persistence {
+	return Persistence{
+		Loading:                 parseBool(values["loading"]),
+		RDBChangesSinceLastSave: parseInt(values["rdb_changes_since_last_save"]),
+		RDBBgsaveInProgress:     parseBool(values["rdb_bgsave_in_progress"]),
+		RDBLastSaveTime:         parseInt(values["rdb_last_save_time"]),
+		RDBLastBgsaveStatus:     values["rdb_last_bgsave_status"],
+		AOFEnabled:              parseBool(values["aof_enabled"]),
+		AOFRewriteInProgress:    parseBool(values["aof_rewrite_in_progress"]),
+		AOFLastWriteStatus:      values["aof_last_write_status"],
+	}
+}
+
+func parseReplication(values map[string]string) Replication {
+	res := Replication{
+		Role:             values["role"],
+		MasterHost:       values["master_host"],
+		MasterPort:       parseInt(values["master_port"]),
+		MasterLinkStatus: values["master_link_status"],
+		// -1 signals that there was no io yet
+		MasterLastIOSecondsAgo: -1,
+		MasterSyncInProgress:   parseBool(values["master_sync_in_progress"]),
+		SlaveReplOffset:        parseInt(values["slave_repl_offset"]),
+		ConnectedSlaves:        parseInt(values["connected_slaves"]),
+		MasterReplID:           values["master_replid"],
+		MasterReplOffset:       parseInt(values["master_repl_offset"]),
+	}
+	if value, ok := values["master_last_io_seconds_ago"]; ok {
+		res.MasterLastIOSecondsAgo = parseInt(value)
+	}
+
+	// The replicas are listed as slave0, slave1, ...
+	for i := 0; ; i++ {
+		value, ok := values["slave"+strconv.Itoa(i)]
+		if !ok {
+			break
+		}
+		res.Replicas = append(res.Replicas, parseReplica(value))
+	}
+
+	return res
+}
+
+func parseKeyspace(values map[string]string) map[int]Keyspace {
+	res := make(map[int]Keyspace, len(values))
+	for key, value := range values {
+		indexStr, ok := strings.CutPrefix(key, "db")
+		if !ok {
+			continue
+		}
+		index, err := strconv.Atoi(indexStr)
+		if err != nil {
+			continue
+		}
+		fields := parseFields(value)
+		res[index] = Keyspace{
+			Keys:    parseInt(fields["keys"]),
+			Expires: parseInt(fields["expires"]),
+			AvgTTL:  parseInt(fields["avg_ttl"]),
+		}
+	}
+	return res
+}
+
+// Parse a replica in the format "ip=10.0.0.1,port=6379,state=online,offset=42,lag=0"
+func parseReplica(value string) Replica {
+	fields := parseFields(value)
+	return Replica{
+		IP:     fields["ip"],
+		Port:   parseInt(fields["port"]),
+		State:  fields["state"],
+		Offset: parseInt(fields["offset"]),
+		Lag:    parseInt(fields["lag"]),
+	}
+}
+
+// Parse a list of fields in the format "key1=value1,key2=value2"
+func parseFields(value string) map[string]string {
+	res := make(map[string]string)
+	for _, field := range strings.Split(value, ",") {
+		k, v, ok := strings.Cut(field, "=")
+		if ok {
+			res[k] = v
+		}
+	}
+	return res
+}
+
+// Parse a number, returning 0 if the value is not a valid number
+func parseInt(value string) int64 {
+	res, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return res
+}
+
+// Parse a flag, where valkey uses 1 for true and 0 for false
+func parseBool(value string) bool {
+	return value == "1"
+}
@@ -0,0 +1,89 @@
+package valkeyinfo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseReplicationSlave(t *testing.T) {
+	assert := assert.New(t)
+
+	res := Parse("# Replication\r\nrole:slave\r\nmaster_host:10.88.0.170\r\nmaster_port:6380\r\nmaster_link_status:down\r\nmaster_last_io_seconds_ago:-1\r\nmaster_sync_in_progress:1\r\nslave_repl_offset:42\r\nconnected_slaves:0\r\nmaster_repl_offset:50\r\n").Replication
+
+	assert.Equal(Replication{
+		Role:                   RoleSlave,
+		MasterHost:             "10.88.0.170",
+		MasterPort:             6380,
+		MasterLinkStatus:       LinkStatusDown,
+		MasterLastIOSecondsAgo: -1,
+		MasterSyncInProgress:   true,
+		SlaveReplOffset:        42,
+		MasterReplOffset:       50,
+	}, res, "Should parse slave")
+
+	assert.True(res.IsSlaveOf("10.88.0.170", 6380), "Should be slave of master")
+	assert.False(res.IsSlaveOf("10.88.0.170", 6379), "Should not be slave of other port")
+	assert.False(res.IsSlaveOf("10.88.0.171", 6380), "Should not be slave of other host")
+	assert.Equal(int64(42), res.Offset(), "Should use the slave offset")
+}
+
+func TestReplicationOffset(t *testing.T) {
+	assert := assert.New(t)
+
+	r := Replication{Role: RoleMaster, MasterReplOffset: 10, SlaveReplOffset: 5}
+	assert.Equal(int64(10), r.Offset(), "Should use the master offset for masters")
+	assert.False(r.IsSlaveOf("", 0), "Master should not be a slave")
+}
+
+func TestParseReplica(t *testing.T) {
+	tMatrix := map[string]struct {
+		input  string
+		result Replica
+	}{
+		"Full": {
+			input:  "ip=10.0.0.1,port=6379,state=online,offset=42,lag=1",
+			result: Replica{IP: "10.0.0.1", Port: 6379, State: "online", Offset: 42, Lag: 1},
+		},
+		"IPv6": {
+			input:  "ip=2001:db8::1,port=6379,state=online,offset=42,lag=0,type=replica",
+			result: Replica{IP: "2001:db8::1", Port: 6379, State: "online", Offset: 42},
+		},
+		"Invalid": {
+			input:  "ip,port=abc",
+			result: Replica{},
+		},
+	}
+
+	for name, tCase := range tMatrix {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tCase.result, parseReplica(tCase.input))
+		})
+	}
+}
+
+func TestParseServerFallback(t *testing.T) {
+	assert := assert.New(t)
+
+	res := Parse("# Server\r\nredis_version:7.2.4\r\nredis_mode:standalone\r\nrun_id:abc\r\n").Server
+
+	assert.Equal("7.2.4", res.Version, "Should fall back to redis_version")
+	assert.Equal("standalone", res.Mode, "Should fall back to redis_mode")
+	assert.Equal("abc", res.RunID, "Should parse run_id")
+}
+
+func TestParseKeyspace(t *testing.T) {
+	assert := assert.New(t)
+
+	res := Parse("# Keyspace\r\ndb0:keys=12,expires=2,avg_ttl=3000,subexpiry=0\r\ndb3:keys=5,expires=0,avg_ttl=0\r\ndbinvalid:keys=1\r\n")
+
+	assert.Equal(map[int]Keyspace{
+		0: {Keys: 12, Expires: 2, AvgTTL: 3000},
+		3: {Keys: 5},
+	}, res.Keyspace, "Should parse all databases")
+	assert.Equal(int64(17), res.Keys(), "Should sum the keys of all databases")
+
+	res = Parse("# Keyspace\r\n")
+	assert.Empty(res.Keyspace, "Should have no databases")
+	assert.Zero(res.Keys(), "Should have no keys")
+}
@@ -6,7 +6,7 @@ import (
 	"testing"
 	"time"
 
-	failoverclient "github.com/heathcliff26/valkey-keepalived/pkg/failover-client"
+	valkeyinfo "github.com/heathcliff26/valkey-keepalived/pkg/valkey-info"
 	"github.com/heathcliff26/valkey-keepalived/tests/utils"
 	"github.com/stretchr/testify/require"
 	"github.com/valkey-io/valkey-go"
@@ -81,7 +81,7 @@ func TestE2E(t *testing.T) {
 				expectedRole = "master"
 			}
 
-			role := failoverclient.ParseValueFromInfo(res, "role")
+			role := valkeyinfo.Parse(res).Replication.Role
 			if expectedRole != role {
 				t.Logf("Node %d has role \"%s\" but should have \"%s\"", i, role, expectedRole)
 				return false